@@ -9,6 +9,7 @@ import (
 	"go/parser"
 	"go/token"
 	"io/fs"
+	"net/http"
 	"os"
 	"os/exec"
 	"path"
@@ -130,6 +131,10 @@ var (
 	GoClientFile    = flag.String("go-client-file", "", "Output Go source file with type definitions and TClient demos (e.g., 'internal/client/api_client.go')")
 	GoClientApis    = flag.String("go-client-apis", "", "Comma-separated API patterns to include (format: 'METHOD:path' or 'path', e.g., 'POST:/api/user,GET:/api/order/*')")
 	GoClientCompile = flags.BoolVal("go-client-compile", false, "Whether the generated Go client file should compile (false adds //go:build miso_gen_do_not_build)", false)
+	RouteDocJson    = flag.String("route-doc-json", "", "Output file for the collected HttpRouteDoc list in JSON (e.g., 'doc/route-doc.json'), it can be loaded by -mock-docs")
+	Mock            = flag.String("mock", "", "Serve mock http server on the address (e.g., ':8080') using the collected HttpRouteDoc list, single module only")
+	MockDocs        = flag.String("mock-docs", "", "Load HttpRouteDoc list from the JSON file dumped by -route-doc-json instead of parsing source files, used with -mock")
+	MockFixtures    = flag.String("mock-fixtures", "", "JSON file of fixtures that override sample responses of the mock server, keyed by 'METHOD URL', e.g., {\"GET /open/api/order/:id\": {\"status\": 200, \"body\": {}}}")
 )
 
 // perfLog logs at INFO level only when the -perf flag is set.
@@ -226,6 +231,23 @@ func main() {
 		}
 	}
 
+	// Serve mock server using the dumped HttpRouteDoc list, source files are not parsed.
+	if *Mock != "" && *MockDocs != "" {
+		var docs []miso.HttpRouteDoc
+		buf, err := os.ReadFile(*MockDocs)
+		if err == nil {
+			err = json.Unmarshal(buf, &docs)
+		}
+		if err != nil {
+			log.Errorf("failed to load HttpRouteDoc list from %v, %v", *MockDocs, err)
+			return
+		}
+		if err := serveMock(docs); err != nil {
+			log.Errorf("serveMock failed, %v", err)
+		}
+		return
+	}
+
 	// Check if go.mod exists in the current directory.
 	_, goModErr := os.Stat("go.mod")
 	if goModErr == nil {
@@ -242,6 +264,10 @@ func main() {
 	}
 
 	log.Infof("Monorepo detected: %d module(s) found", len(modDirs))
+	if *Mock != "" {
+		log.Errorf("-mock is not supported in monorepo, use -route-doc-json and -mock-docs instead")
+		return
+	}
 
 	origDir, err := os.Getwd()
 	if err != nil {
@@ -1607,7 +1633,6 @@ func generateDocs(dir string, skipPkgs []string, appName string, port string) er
 	seenGoTypes := hash.NewSet[string]()
 	for i := range allDocs {
 		d := &allDocs[i]
-		d.Curl = miso.GenRouteCurl(*d, port)
 
 		// Generate Go/Ts definitions for request types.
 		// Only POST/PUT have request bodies — empty structs (e.g. EmptyReq{}) are
@@ -1616,7 +1641,7 @@ func generateDocs(dir string, skipPkgs []string, appName string, port string) er
 		// "type  struct { }" for zero-value TypeDesc (TypeName="").
 		jsonReqGoDef, jsonReqGoDefTypeName := miso.GenGoDef(d.JsonRequestDesc, hash.NewSet[string]())
 		if jsonReqGoDef != "" && d.JsonRequestDesc.TypeName != "" &&
-			(d.Method == "POST" || d.Method == "PUT") {
+			(d.Method == "POST" || d.Method == "PUT" || d.WebSocket) {
 			d.JsonReqTsDef = miso.GenTsDef(d.JsonRequestDesc)
 			d.JsonTsDef = d.JsonReqTsDef
 			d.JsonReqGoDef = jsonReqGoDef
@@ -1631,6 +1656,13 @@ func generateDocs(dir string, skipPkgs []string, appName string, port string) er
 			d.JsonRespGoDef, d.JsonRespGoDefTypeName = miso.GenGoDef(d.JsonResponseDesc, hash.NewSet[string]())
 		}
 
+		// http client demos are not applicable to websocket endpoints
+		if d.WebSocket {
+			continue
+		}
+
+		d.Curl = miso.GenRouteCurl(*d, port)
+
 		// Miso HTTP Client demo
 		rawTClient := miso.GenTClientDemo(*d, appName)
 
@@ -1713,5 +1745,58 @@ func generateDocs(dir string, skipPkgs []string, appName string, port string) er
 	perfLog("GenMarkDownDoc + write elapsed: %v", time.Since(mdStart))
 
 	log.Infof("API docs written to %s", docFilePath)
+
+	if *RouteDocJson != "" {
+		if err := writeRouteDocJson(dir, allDocs); err != nil {
+			return err
+		}
+	}
+
+	if *Mock != "" {
+		return serveMock(allDocs)
+	}
+	return nil
+}
+
+// writeRouteDocJson dumps the HttpRouteDoc list as JSON, which can be loaded by -mock-docs.
+func writeRouteDocJson(dir string, docs []miso.HttpRouteDoc) error {
+	p := filepath.Join(dir, *RouteDocJson)
+	buf, err := json.MarshalIndent(docs, "", "  ")
+	if err != nil {
+		return errs.Wrapf(err, "failed to marshal HttpRouteDoc list")
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return errs.Wrapf(err, "failed to create output directory for %s", p)
+	}
+	if err := os.WriteFile(p, buf, 0644); err != nil {
+		return errs.Wrapf(err, "failed to write HttpRouteDoc list file %s", p)
+	}
+	log.Infof("HttpRouteDoc list written to %s", p)
 	return nil
 }
+
+// serveMock serves mock http server on the address specified by -mock, it blocks until the server is closed.
+func serveMock(docs []miso.HttpRouteDoc) error {
+	fixtures := map[string]miso.MockFixture{}
+	if *MockFixtures != "" {
+		buf, err := os.ReadFile(*MockFixtures)
+		if err != nil {
+			return errs.Wrapf(err, "failed to read mock fixtures file %s", *MockFixtures)
+		}
+		if err := json.Unmarshal(buf, &fixtures); err != nil {
+			return errs.Wrapf(err, "failed to unmarshal mock fixtures file %s", *MockFixtures)
+		}
+	}
+	ms := miso.NewMockServer(docs, func(c *miso.MockServerConfig) {
+		for k, v := range fixtures {
+			c.Fixtures[k] = v
+		}
+	})
+	for _, d := range docs {
+		if !d.WebSocket {
+			log.Infof("Mock %-7s %s", d.Method, d.Url)
+		}
+	}
+	log.Infof("Mock server listening on %s", *Mock)
+	return http.ListenAndServe(*Mock, ms)
+}
@@ -54,6 +54,7 @@ type MisoErr struct {
 	internalMsg string // internal message that is only logged on server.
 	stack       string
 	err         error
+	httpStatus  int // http status returned to the client, 0 means the default status.
 }
 
 func (e *MisoErr) Cause() error {
@@ -76,6 +77,11 @@ func (e *MisoErr) StackTrace() string {
 	return e.stack
 }
 
+// Http status returned to the client, 0 means the default status is used.
+func (e *MisoErr) HttpStatus() int {
+	return e.httpStatus
+}
+
 // Create new *MisoErr to wrap the cause error
 //
 // if cause is nil, nil is returned.
@@ -114,6 +120,7 @@ func (e *MisoErr) copyNew() *MisoErr {
 	n.internalMsg = e.internalMsg
 	n.stack = e.stack
 	n.err = e.err
+	n.httpStatus = e.httpStatus
 	return n
 }
 
@@ -123,6 +130,7 @@ func (e *MisoErr) New() error {
 	n.msg = e.msg
 	n.internalMsg = e.internalMsg
 	n.err = e.err
+	n.httpStatus = e.httpStatus
 	n.withStack()
 	return n
 }
@@ -153,6 +161,13 @@ func (e *MisoErr) WithCode(code string) *MisoErr {
 	return n
 }
 
+// Create new *MisoErr with the http status that should be returned to the client.
+func (e *MisoErr) WithHttpStatus(status int) *MisoErr {
+	n := e.copyNew()
+	n.httpStatus = status
+	return n
+}
+
 func (e *MisoErr) WithMsg(msg string) *MisoErr {
 	n := e.copyNew()
 	n.msg = msg
@@ -0,0 +1,31 @@
+package flow
+
+type peerIdentityKey struct{}
+
+// Identity of the peer, verified using the client certificate (mTLS).
+type PeerIdentity struct {
+	CommonName   string   `json:"commonName"`
+	Organization []string `json:"organization"`
+	DNSNames     []string `json:"dnsNames"`
+	URIs         []string `json:"uris"`
+	SerialNumber string   `json:"serialNumber"`
+	Issuer       string   `json:"issuer"`
+}
+
+// Get PeerIdentity from Rail, returns false if the peer is not verified.
+func GetPeerIdentity(rail Rail) (PeerIdentity, bool) {
+	v, ok := rail.Value(peerIdentityKey{}).(PeerIdentity)
+	return v, ok
+}
+
+// Store PeerIdentity in Rail.
+//
+// PeerIdentity is not propagated to the downstream services.
+func StorePeerIdentity(rail Rail, p PeerIdentity) Rail {
+	return rail.WithCtxVal(peerIdentityKey{}, p)
+}
+
+// Get verified PeerIdentity, returns false if the peer is not verified.
+func (r Rail) PeerIdentity() (PeerIdentity, bool) {
+	return GetPeerIdentity(r)
+}
@@ -394,6 +394,34 @@ func (q *Query) OrderAsc(col string) *Query {
 	return q
 }
 
+// Add keyset predicate that selects records after the values of the ordered columns.
+//
+// E.g., for columns (created_at DESC, id ASC), the predicate is 'created_at < ? OR (created_at = ? AND id > ?)'.
+func (q *Query) Keyset(cols []CursorCol, values []any) *Query {
+	n := min(len(cols), len(values))
+	if n < 1 {
+		return q
+	}
+	ors := make([]string, 0, n)
+	args := make([]any, 0, n*(n+1)/2)
+	for i := 0; i < n; i++ {
+		ands := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			ands = append(ands, cols[j].Col+" = ?")
+			args = append(args, values[j])
+		}
+		op := " > ?"
+		if cols[i].Desc {
+			op = " < ?"
+		}
+		ands = append(ands, cols[i].Col+op)
+		args = append(args, values[i])
+		ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+	}
+	q.tx = q.tx.Where("("+strings.Join(ors, " OR ")+")", args...)
+	return q
+}
+
 // Same as [Query.Joins].
 func (q *Query) Join(query string, args ...any) *Query {
 	return q.Joins(query, args...)
@@ -1002,6 +1030,14 @@ type PageQuery[V any] struct {
 	baseQuery   ChainedPageQuery          // Base query, e.g., return tx.Table(`myTable`).Where(...)
 	mapTo       func(t V) V               // callback triggered on each record, the value returned will overwrite the value passed in.
 	mapToAsync  func(t V) async.Future[V] // callback triggered on each record, the value returned will overwrite the value passed in.
+	cursorCols  []CursorCol               // ordered columns for cursor pagination.
+	getCursor   func(t V) []any           // values of the cursorCols in the record.
+}
+
+// Ordered column used for cursor (keyset) pagination.
+type CursorCol struct {
+	Col  string // column name
+	Desc bool   // whether the column is ordered in descending order
 }
 
 func NewPagedQuery[V any](db *gorm.DB) *PageQuery[V] {
@@ -1028,6 +1064,17 @@ func (pq *PageQuery[V]) TransformAsync(t func(t V) async.Future[V]) *PageQuery[V
 	return pq
 }
 
+// Use cursor (keyset) pagination ordered by the columns, the last column should be unique (e.g., the id) as a tie-breaker.
+//
+// getCursor returns the values of the columns in the record, e.g., return []any{v.CreatedAt, v.Id}.
+//
+// The ORDER BY clause is added by [PageQuery.ScanCursor], the select query should not add ORDER BY.
+func (pq *PageQuery[V]) WithCursor(getCursor func(t V) []any, cols ...CursorCol) *PageQuery[V] {
+	pq.getCursor = getCursor
+	pq.cursorCols = cols
+	return pq
+}
+
 type IteratePageParam struct {
 	Limit int `json:"limit" desc:"page limit"`
 }
@@ -1092,6 +1139,59 @@ func (pq *PageQuery[V]) IterateAllPages(rail miso.Rail, param IteratePageParam,
 	}
 }
 
+// Scan page using cursor (keyset) pagination, see [PageQuery.WithCursor].
+//
+// Instead of OFFSET, the records after the cursor are selected using predicates built from the ordered columns, e.g.,
+// for columns (created_at DESC, id ASC), the predicate is 'created_at < ? OR (created_at = ? AND id > ?)'.
+//
+// The cursor of the next page is returned in CursorPageRes, it's empty if there are no more records.
+func (pq *PageQuery[V]) ScanCursor(rail miso.Rail, reqPage miso.CursorPaging) (miso.CursorPageRes[V], error) {
+	var res miso.CursorPageRes[V]
+	if len(pq.cursorCols) < 1 || pq.getCursor == nil {
+		return res, errs.NewErrf("cursor columns are not specified, use PageQuery.WithCursor(..)")
+	}
+
+	qry := pq.baseQuery(NewQuery(rail, pq.db))
+	if reqPage.Cursor != "" {
+		values, err := miso.DecodeCursor(reqPage.Cursor)
+		if err != nil {
+			return res, err
+		}
+		if len(values) != len(pq.cursorCols) {
+			return res, miso.ErrInvalidCursor.WithInternalMsg("expected %v cursor values, got %v", len(pq.cursorCols), len(values))
+		}
+		qry = qry.Keyset(pq.cursorCols, values)
+	}
+	if pq.selectQuery != nil {
+		qry = pq.selectQuery(qry)
+	}
+	for _, c := range pq.cursorCols {
+		if c.Desc {
+			qry = qry.OrderDesc(c.Col)
+		} else {
+			qry = qry.OrderAsc(c.Col)
+		}
+	}
+
+	limit := reqPage.GetLimit()
+	var payload []V
+	if _, err := qry.Limit(limit + 1).Scan(&payload); err != nil {
+		return res, err
+	}
+
+	var next string
+	if len(payload) > limit {
+		payload = payload[:limit]
+		c, err := miso.EncodeCursor(pq.getCursor(payload[len(payload)-1])...)
+		if err != nil {
+			return res, err
+		}
+		next = c
+	}
+	payload = pq.transform(rail, payload)
+	return miso.CursorPageRes[V]{Payload: payload, Page: miso.RespCursorPage(reqPage, next)}, nil
+}
+
 func (pq *PageQuery[V]) Scan(rail miso.Rail, reqPage miso.Paging) (miso.PageRes[V], error) {
 	return pq.scan(rail, reqPage, true)
 }
@@ -1122,28 +1222,7 @@ func (pq *PageQuery[V]) scan(rail miso.Rail, reqPage miso.Paging, doCount bool)
 		if err != nil {
 			return nil, err
 		}
-
-		if pq.mapTo != nil {
-			for i := range payload {
-				payload[i] = pq.mapTo(payload[i])
-			}
-		}
-
-		if pq.mapToAsync != nil {
-			futures := make([]async.Future[V], 0, len(payload))
-			for _, p := range payload {
-				futures = append(futures, pq.mapToAsync(p))
-			}
-			for i := range payload {
-				v, err := futures[i].Get()
-				if err != nil {
-					rail.Warnf("Failed to resolve Future, skipped %v", err)
-					continue
-				}
-				payload[i] = v
-			}
-		}
-		return payload, nil
+		return pq.transform(rail, payload), nil
 	})
 
 	var res miso.PageRes[V]
@@ -1165,6 +1244,30 @@ func (pq *PageQuery[V]) scan(rail miso.Rail, reqPage miso.Paging, doCount bool)
 	return res, nil
 }
 
+func (pq *PageQuery[V]) transform(rail miso.Rail, payload []V) []V {
+	if pq.mapTo != nil {
+		for i := range payload {
+			payload[i] = pq.mapTo(payload[i])
+		}
+	}
+
+	if pq.mapToAsync != nil {
+		futures := make([]async.Future[V], 0, len(payload))
+		for _, p := range payload {
+			futures = append(futures, pq.mapToAsync(p))
+		}
+		for i := range payload {
+			v, err := futures[i].Get()
+			if err != nil {
+				rail.Warnf("Failed to resolve Future, skipped %v", err)
+				continue
+			}
+			payload[i] = v
+		}
+	}
+	return payload
+}
+
 type IterateByOffset1Param[V, Offset any] struct {
 	Limit       int    // limit, by default 100
 	OffsetCol   string // col name in ORDER BY (col)
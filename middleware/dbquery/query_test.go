@@ -0,0 +1,176 @@
+package dbquery
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/curtisnewbie/miso/miso"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1) // each connection has its own in-memory database
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+func TestKeyset(t *testing.T) {
+	db := newTestDB(t).Session(&gorm.Session{DryRun: true})
+
+	cases := []struct {
+		cols   []CursorCol
+		values []any
+		where  string
+		vars   []any
+	}{
+		{
+			cols:   []CursorCol{{Col: "id"}},
+			values: []any{int64(10)},
+			where:  "WHERE ((id > ?))",
+			vars:   []any{int64(10)},
+		},
+		{
+			cols:   []CursorCol{{Col: "id", Desc: true}},
+			values: []any{int64(10)},
+			where:  "WHERE ((id < ?))",
+			vars:   []any{int64(10)},
+		},
+		{
+			cols:   []CursorCol{{Col: "score", Desc: true}, {Col: "id"}},
+			values: []any{int64(5), int64(10)},
+			where:  "WHERE ((score < ?) OR (score = ? AND id > ?))",
+			vars:   []any{int64(5), int64(5), int64(10)},
+		},
+		{
+			cols:   []CursorCol{{Col: "a"}, {Col: "b", Desc: true}, {Col: "id"}},
+			values: []any{"x", int64(2), int64(3)},
+			where:  "WHERE ((a > ?) OR (a = ? AND b < ?) OR (a = ? AND b = ? AND id > ?))",
+			vars:   []any{"x", "x", int64(2), "x", int64(2), int64(3)},
+		},
+		{
+			cols:   []CursorCol{{Col: "id"}},
+			values: nil, // first page
+			where:  "",
+			vars:   []any{},
+		},
+	}
+	for _, c := range cases {
+		var rows []map[string]any
+		stmt := NewQuery(db).Table("item").Keyset(c.cols, c.values).tx.Find(&rows).Statement
+		sql := stmt.SQL.String()
+		if c.where == "" {
+			if strings.Contains(sql, "WHERE") {
+				t.Fatalf("unexpected predicate: %v", sql)
+			}
+			continue
+		}
+		if !strings.HasSuffix(sql, c.where) {
+			t.Fatalf("expected predicate '%v', got '%v'", c.where, sql)
+		}
+		if !reflect.DeepEqual(stmt.Vars, c.vars) {
+			t.Fatalf("expected vars %v, got %v", c.vars, stmt.Vars)
+		}
+	}
+}
+
+type cursorTestItem struct {
+	Id    int64
+	Score int
+	Name  string
+}
+
+func TestScanCursor(t *testing.T) {
+	db := newTestDB(t)
+	if err := db.Exec("CREATE TABLE item (id INTEGER PRIMARY KEY, score INTEGER, name TEXT)").Error; err != nil {
+		t.Fatal(err)
+	}
+	scores := []int{3, 1, 3, 2, 1, 3, 2}
+	for i, s := range scores {
+		if err := db.Exec("INSERT INTO item (id, score, name) VALUES (?, ?, ?)", i+1, s, fmt.Sprintf("item-%d", i+1)).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rail := miso.EmptyRail()
+	scanAll := func(limit int, cols ...CursorCol) []int64 {
+		pq := NewPagedQuery[cursorTestItem](db).
+			WithBaseQuery(func(q *Query) *Query { return q.Table("item") }).
+			WithSelectQuery(func(q *Query) *Query { return q.Select("id, score, name") }).
+			WithCursor(func(v cursorTestItem) []any {
+				if len(cols) == 1 {
+					return []any{v.Id}
+				}
+				return []any{v.Score, v.Id}
+			}, cols...)
+
+		var ids []int64
+		page := miso.CursorPaging{Limit: limit}
+		for i := 0; ; i++ {
+			res, err := pq.ScanCursor(rail, page)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(res.Payload) > limit {
+				t.Fatalf("page %v exceeds limit, %v", i, len(res.Payload))
+			}
+			for _, v := range res.Payload {
+				ids = append(ids, v.Id)
+			}
+			if res.Page.HasMore != (res.Page.Cursor != "") {
+				t.Fatalf("unexpected page: %+v", res.Page)
+			}
+			if !res.Page.HasMore {
+				break
+			}
+			if i > len(scores) {
+				t.Fatal("too many pages")
+			}
+			page.Cursor = res.Page.Cursor
+		}
+		return ids
+	}
+
+	// single column, ASC
+	if ids := scanAll(3, CursorCol{Col: "id"}); !reflect.DeepEqual(ids, []int64{1, 2, 3, 4, 5, 6, 7}) {
+		t.Fatalf("unexpected ids: %v", ids)
+	}
+
+	// single column, DESC
+	if ids := scanAll(2, CursorCol{Col: "id", Desc: true}); !reflect.DeepEqual(ids, []int64{7, 6, 5, 4, 3, 2, 1}) {
+		t.Fatalf("unexpected ids: %v", ids)
+	}
+
+	// multiple columns, score DESC, id ASC as tie-breaker, pages split rows with the same score
+	if ids := scanAll(2, CursorCol{Col: "score", Desc: true}, CursorCol{Col: "id"}); !reflect.DeepEqual(ids, []int64{1, 3, 6, 4, 7, 2, 5}) {
+		t.Fatalf("unexpected ids: %v", ids)
+	}
+
+	// multiple columns, score ASC, id DESC
+	if ids := scanAll(3, CursorCol{Col: "score"}, CursorCol{Col: "id", Desc: true}); !reflect.DeepEqual(ids, []int64{5, 2, 7, 4, 6, 3, 1}) {
+		t.Fatalf("unexpected ids: %v", ids)
+	}
+
+	// cursor doesn't match the columns
+	c, err := miso.EncodeCursor(int64(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pq := NewPagedQuery[cursorTestItem](db).
+		WithBaseQuery(func(q *Query) *Query { return q.Table("item") }).
+		WithCursor(func(v cursorTestItem) []any { return []any{v.Score, v.Id} }, CursorCol{Col: "score"}, CursorCol{Col: "id"})
+	if _, err := pq.ScanCursor(rail, miso.CursorPaging{Cursor: c}); err == nil {
+		t.Fatal("mismatched cursor should be rejected")
+	}
+}
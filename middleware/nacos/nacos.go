@@ -194,6 +194,11 @@ func (m *nacosModule) initDiscovery(rail miso.Rail) (bool, error) {
 	// is deregistered before shutting down the web server
 	miso.AddOrderedShutdownHook(miso.DefShutdownOrder-1, func() {
 		rail := miso.EmptyRail()
+		if miso.IsGracefulRestarting() {
+			// the new process has registered itself using the same address
+			rail.Infof("Graceful restart in progress, skipped Nacos deregistration")
+			return
+		}
 		rail.Infof("Deregistering Nacos service")
 		if e := deregisterNacosService(m.serverList.client); e != nil {
 			rail.Errorf("Failed to deregister on Nacos, %v", e)
@@ -605,10 +610,17 @@ func (s *NacosServerList) ListServers(rail miso.Rail, name string) []miso.Server
 
 	return slutil.UpdateTransform(inst,
 		slutil.MapFunc(func(v model.Instance) miso.Server {
+			meta := hash.MapCopy(v.Metadata)
+			if meta == nil {
+				meta = map[string]string{}
+			}
+			if _, ok := meta[miso.ServerMetaWeight]; !ok {
+				meta[miso.ServerMetaWeight] = cast.ToString(v.Weight)
+			}
 			return miso.Server{
 				Address: v.Ip,
 				Port:    int(v.Port),
-				Meta:    hash.MapCopy(v.Metadata),
+				Meta:    meta,
 			}
 		}),
 		slutil.FilterFunc(func(i model.Instance) bool {
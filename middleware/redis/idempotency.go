@@ -0,0 +1,81 @@
+package redis
+
+import (
+	"time"
+
+	"github.com/curtisnewbie/miso/miso"
+)
+
+type idempotencyStore struct {
+	inflight RCache[miso.IdempotencyRecord]
+	cache    RCache[miso.IdempotencyRecord]
+}
+
+func (s *idempotencyStore) Begin(rail miso.Rail, key string, rec miso.IdempotencyRecord) (miso.IdempotencyRecord, bool, error) {
+	for {
+		prev, ok, err := s.cache.Get(rail, key)
+		if err != nil {
+			return prev, false, err
+		}
+		if ok {
+			return prev, false, nil
+		}
+
+		ok, err = s.inflight.PutIfAbsent(rail, key, rec)
+		if err != nil {
+			return rec, false, err
+		}
+		if ok {
+			// the previous request may be completed right before the in-flight record is put
+			prev, ok, err := s.cache.Get(rail, key)
+			if err != nil {
+				return rec, false, err
+			}
+			if !ok {
+				return rec, true, nil
+			}
+			return prev, false, s.inflight.Del(rail, key)
+		}
+
+		prev, ok, err = s.inflight.Get(rail, key)
+		if err != nil {
+			return prev, false, err
+		}
+		if ok {
+			return prev, false, nil
+		}
+		// just completed, aborted or expired, try again
+	}
+}
+
+func (s *idempotencyStore) Complete(rail miso.Rail, key string, rec miso.IdempotencyRecord) error {
+	if err := s.cache.Put(rail, key, rec); err != nil {
+		return err
+	}
+	return s.inflight.Del(rail, key)
+}
+
+func (s *idempotencyStore) Abort(rail miso.Rail, key string) error {
+	return s.inflight.Del(rail, key)
+}
+
+// Create Redis based miso.IdempotencyStore.
+//
+// Completed records are kept for ttl, in-flight records are kept for lease, such that the retries are no longer
+// rejected if the first request is lost. The lease should be longer than the time it takes to process the request.
+func NewIdempotencyStore(ttl time.Duration, lease time.Duration) miso.IdempotencyStore {
+	return &idempotencyStore{
+		inflight: NewRCache[miso.IdempotencyRecord]("miso:idempotency-inflight", RCacheConfig{Exp: lease, NoSync: true}),
+		cache:    NewRCache[miso.IdempotencyRecord]("miso:idempotency", RCacheConfig{Exp: ttl, NoSync: true}),
+	}
+}
+
+// Use Redis based miso.IdempotencyStore for endpoints declared with miso.LazyRouteDecl.Idempotent(),
+// such that retries are deduplicated among all instances.
+//
+// See [NewIdempotencyStore] for ttl and lease.
+//
+// Must be called before the web server bootstraps.
+func UseDistributedIdempotencyStore(ttl time.Duration, lease time.Duration) {
+	miso.SetIdempotencyStore(NewIdempotencyStore(ttl, lease))
+}
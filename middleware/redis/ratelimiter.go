@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/curtisnewbie/miso/errs"
+	"github.com/curtisnewbie/miso/miso"
 	"github.com/curtisnewbie/miso/util/async"
 	"github.com/go-redis/redis_rate/v10"
 )
@@ -51,3 +52,31 @@ func (r *rateLimiter) AcquireWait(wait time.Duration) (bool, error) {
 		return false, false, nil
 	})
 }
+
+// Acquire permit for the key, implements miso.RouteRateLimiter.
+//
+// Keys are isolated from each other, but they share the same rate limit (max requests per period).
+func (r *rateLimiter) AcquireKey(rail miso.Rail, key string) (bool, time.Duration, error) {
+	res, err := r.limiter.Allow(rail.Context(), r.name+":"+key, redis_rate.Limit{
+		Rate:   r.max,
+		Burst:  r.max,
+		Period: r.period,
+	})
+	if err != nil {
+		return false, 0, errs.Wrap(err)
+	}
+	if res.Allowed > 0 {
+		return true, 0, nil
+	}
+	return false, res.RetryAfter, nil
+}
+
+// Use Redis based rate limiter for endpoints declared with miso.LazyRouteDecl.RateLimit(...),
+// such that the rate limits are shared among all instances.
+//
+// Must be called before the web server bootstraps.
+func UseDistributedRouteRateLimiter() {
+	miso.SetRouteRateLimiterFactory(func(name string, max int, period time.Duration) miso.RouteRateLimiter {
+		return NewRateLimiter("miso:route:ratelimit:"+name, max, period)
+	})
+}
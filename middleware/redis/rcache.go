@@ -65,6 +65,17 @@ func (r *RCache[T]) Put(rail miso.Rail, key string, t T) error {
 	return op()
 }
 
+// Put the value only if the key is absent, returns true if the value is put.
+func (r *RCache[T]) PutIfAbsent(rail miso.Rail, key string, t T) (bool, error) {
+	cacheKey := r.cacheKey(key)
+	val, err := r.ValueSerializer.Serialize(t)
+	if err != nil {
+		return false, r.wrapErr(err, key)
+	}
+	ok, err := r.getClient().SetNX(rail.Context(), cacheKey, val, r.exp).Result()
+	return ok, r.wrapErr(err, key)
+}
+
 func (r *RCache[T]) RefreshTTL(rail miso.Rail, key string) error {
 	cacheKey := r.cacheKey(key)
 	op := func() error {
@@ -206,17 +217,40 @@ func (r *RCache[T]) Exists(rail miso.Rail, key string) (bool, error) {
 }
 
 func (r *RCache[T]) DelAll(rail miso.Rail) error {
-	return r.doScanAll(rail, func(keys []string) error {
+	return r.doScanAll(rail, r.cacheKeyPattern(), func(keys []string) error {
 		return r.doBatchDel(rail, keys)
 	})
 }
 
+// Delete all keys with the prefix.
+//
+// DelPrefix is O(N) where N is the total number of keys in the redis database.
+//
+// Use with caution.
+func (r *RCache[T]) DelPrefix(rail miso.Rail, prefix string) error {
+	return r.doScanAll(rail, r.cacheKeyPrefix()+escapeScanPattern(prefix)+"*", func(keys []string) error {
+		return r.doBatchDel(rail, keys)
+	})
+}
+
+func escapeScanPattern(s string) string {
+	var b strings.Builder
+	for _, c := range s {
+		switch c {
+		case '*', '?', '[', ']', '\\':
+			b.WriteRune('\\')
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
 // ScanAll is O(N) where N is the total number of keys in the redis database.
 //
 // Use with caution.
 func (r *RCache[T]) ScanAll(rail miso.Rail, f func(keys []string) error) error {
 	prefix := r.cacheKeyPrefix()
-	return r.doScanAll(rail, func(keys []string) error {
+	return r.doScanAll(rail, r.cacheKeyPattern(), func(keys []string) error {
 		slutil.UpdateSliceValue(keys, func(t string) string {
 			t, _ = strings.CutPrefix(t, prefix)
 			return t
@@ -225,9 +259,8 @@ func (r *RCache[T]) ScanAll(rail miso.Rail, f func(keys []string) error) error {
 	})
 }
 
-func (r *RCache[T]) doScanAll(rail miso.Rail, f func(keys []string) error) error {
+func (r *RCache[T]) doScanAll(rail miso.Rail, pat string, f func(keys []string) error) error {
 
-	pat := r.cacheKeyPattern()
 	cmd := r.getClient().Scan(rail.Context(), 0, pat, rcacheScanLimit)
 	if cmd.Err() != nil {
 		return errs.Wrapf(cmd.Err(), "failed to scan redis with pattern '%v'", pat)
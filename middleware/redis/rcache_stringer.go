@@ -26,6 +26,10 @@ func (r *RCacheV2[K, T]) Put(rail miso.Rail, k K, t T) error {
 	return r.c.Put(rail, r.key(k), t)
 }
 
+func (r *RCacheV2[K, T]) PutIfAbsent(rail miso.Rail, k K, t T) (bool, error) {
+	return r.c.PutIfAbsent(rail, r.key(k), t)
+}
+
 func (r *RCacheV2[K, T]) RefreshTTL(rail miso.Rail, k K) error {
 	return r.c.RefreshTTL(rail, r.key(k))
 }
@@ -0,0 +1,53 @@
+package redis
+
+import (
+	"sync"
+	"time"
+
+	"github.com/curtisnewbie/miso/miso"
+)
+
+const responseCacheName = "miso:respcache"
+
+type responseCacheStore struct {
+	mu     sync.Mutex
+	caches map[time.Duration]*RCache[miso.CachedResponse]
+}
+
+// RCache with the same name share the same keys, the ttl only affects Put(..).
+func (s *responseCacheStore) cache(ttl time.Duration) *RCache[miso.CachedResponse] {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.caches[ttl]
+	if !ok {
+		v := NewRCache[miso.CachedResponse](responseCacheName, RCacheConfig{Exp: ttl, NoSync: true})
+		c = &v
+		s.caches[ttl] = c
+	}
+	return c
+}
+
+func (s *responseCacheStore) Get(rail miso.Rail, key string) (miso.CachedResponse, bool, error) {
+	return s.cache(0).Get(rail, key)
+}
+
+func (s *responseCacheStore) Put(rail miso.Rail, key string, r miso.CachedResponse, ttl time.Duration) error {
+	return s.cache(ttl).Put(rail, key, r)
+}
+
+func (s *responseCacheStore) DelPrefix(rail miso.Rail, prefix string) error {
+	return s.cache(0).DelPrefix(rail, prefix)
+}
+
+// Create Redis based miso.ResponseCacheStore.
+func NewResponseCacheStore() miso.ResponseCacheStore {
+	return &responseCacheStore{caches: map[time.Duration]*RCache[miso.CachedResponse]{}}
+}
+
+// Use Redis based miso.ResponseCacheStore for endpoints declared with miso.LazyRouteDecl.Cache(..),
+// such that cached responses are shared and invalidated among all instances.
+//
+// Must be called before the web server bootstraps.
+func UseDistributedResponseCache() {
+	miso.SetResponseCacheStore(NewResponseCacheStore())
+}
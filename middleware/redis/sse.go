@@ -0,0 +1,39 @@
+package redis
+
+import (
+	"github.com/curtisnewbie/miso/miso"
+)
+
+type sseFanoutMessage struct {
+	Channel string        `json:"channel"`
+	Event   miso.SseEvent `json:"event"`
+}
+
+type sseFanout struct {
+	topic *rtopic[sseFanoutMessage]
+}
+
+func (s *sseFanout) Publish(rail miso.Rail, channel string, e miso.SseEvent) error {
+	return s.topic.Publish(rail, sseFanoutMessage{Channel: channel, Event: e})
+}
+
+func (s *sseFanout) Subscribe(rail miso.Rail, deliver func(rail miso.Rail, channel string, e miso.SseEvent)) error {
+	_, err := s.topic.SubscribeSync(func(rail miso.Rail, m sseFanoutMessage) error {
+		deliver(rail, m.Channel, m.Event)
+		return nil
+	})
+	return err
+}
+
+// Create Redis pub/sub based miso.SseFanout, such that events broadcast by miso.SseHub reach clients connected to all instances.
+//
+// E.g.,
+//
+//	var hub = miso.NewSseHub()
+//
+//	func init() {
+//		hub.SetFanout(redis.NewSseFanout("myapp:sse"))
+//	}
+func NewSseFanout(topic string) miso.SseFanout {
+	return &sseFanout{topic: NewTopic[sseFanoutMessage](topic)}
+}
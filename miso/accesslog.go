@@ -0,0 +1,278 @@
+package miso
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/curtisnewbie/miso/util/json"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	AccessLogFormatJson     = "json"
+	AccessLogFormatCombined = "combined"
+
+	ctxKeyAccessLogSampleRate = "miso-AccessLogSampleRate"
+	ctxKeyAccessLogWriter     = "miso-AccessLogWriter"
+)
+
+var (
+	accessLogSink   AccessLogSink
+	accessLogSinkMu sync.Mutex
+)
+
+// Access log entry of an inbound request.
+type AccessLogEntry struct {
+	Time         time.Time           `json:"time"`
+	Method       string              `json:"method"`
+	Route        string              `json:"route"` // route pattern, e.g., '/open/api/order/:id'; empty if no route matches
+	Uri          string              `json:"uri"`
+	Proto        string              `json:"proto"`
+	Status       int                 `json:"status"`
+	LatencyMs    float64             `json:"latencyMs"`
+	BytesIn      int64               `json:"bytesIn"`
+	BytesOut     int                 `json:"bytesOut"`
+	ClientIp     string              `json:"clientIp"`
+	UserAgent    string              `json:"userAgent,omitempty"`
+	Referer      string              `json:"referer,omitempty"`
+	UserNo       string              `json:"userNo,omitempty"`
+	Username     string              `json:"username,omitempty"`
+	TraceId      string              `json:"traceId,omitempty"`
+	SpanId       string              `json:"spanId,omitempty"`
+	Headers      map[string][]string `json:"headers,omitempty"`
+	RequestBody  string              `json:"requestBody,omitempty"`
+	ResponseBody string              `json:"responseBody,omitempty"`
+}
+
+// Sink of access log entries.
+type AccessLogSink interface {
+	Log(e AccessLogEntry)
+}
+
+// Func that implements [AccessLogSink].
+type AccessLogSinkFunc func(e AccessLogEntry)
+
+func (f AccessLogSinkFunc) Log(e AccessLogEntry) {
+	f(e)
+}
+
+// Set custom AccessLogSink, it replaces the default one configured using 'server.access-log.*' props.
+//
+// Must be called before the server bootstraps.
+func SetAccessLogSink(s AccessLogSink) {
+	accessLogSinkMu.Lock()
+	defer accessLogSinkMu.Unlock()
+	accessLogSink = s
+}
+
+// Create AccessLogSink that writes entries to the writer in the format, i.e., [AccessLogFormatJson] or [AccessLogFormatCombined].
+func NewAccessLogWriterSink(w io.Writer, format string) AccessLogSink {
+	return &accessLogWriterSink{w: w, format: format}
+}
+
+type accessLogWriterSink struct {
+	mu     sync.Mutex
+	w      io.Writer
+	format string
+}
+
+func (s *accessLogWriterSink) Log(e AccessLogEntry) {
+	var line []byte
+	if s.format == AccessLogFormatCombined {
+		line = []byte(FormatAccessLogCombined(e))
+	} else {
+		b, err := FormatAccessLogJson(e)
+		if err != nil {
+			Errorf("Failed to format access log, %v", err)
+			return
+		}
+		line = b
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.w.Write(line); err != nil {
+		Errorf("Failed to write access log, %v", err)
+	}
+}
+
+// Format access log entry as a JSON line (without the trailing newline).
+func FormatAccessLogJson(e AccessLogEntry) ([]byte, error) {
+	return json.WriteJson(e)
+}
+
+// Format access log entry in Apache combined log format (without the trailing newline).
+func FormatAccessLogCombined(e AccessLogEntry) string {
+	user := e.Username
+	if user == "" {
+		user = e.UserNo
+	}
+	bytesOut := "-"
+	if e.BytesOut > 0 {
+		bytesOut = strconv.Itoa(e.BytesOut)
+	}
+	return fmt.Sprintf(`%s - %s [%s] "%s %s %s" %d %s "%s" "%s"`,
+		e.ClientIp, combinedLogField(user), e.Time.Format("02/Jan/2006:15:04:05 -0700"), e.Method, e.Uri, e.Proto,
+		e.Status, bytesOut, combinedLogField(e.Referer), combinedLogField(e.UserAgent))
+}
+
+func combinedLogField(v string) string {
+	if v == "" {
+		return "-"
+	}
+	return strings.ReplaceAll(v, `"`, `\"`)
+}
+
+// Override sample rate (0 to 1) of access log for the endpoint, see 'server.access-log.sample-rate'.
+func (g *LazyRouteDecl) AccessLogSampleRate(rate float64) *LazyRouteDecl {
+	return g.intercept(func(c *gin.Context, next func()) {
+		c.Set(ctxKeyAccessLogSampleRate, rate)
+		next()
+	})
+}
+
+// Access log middleware, it's registered automatically when 'server.access-log.enabled' is true.
+//
+// By default, entries are written to the file specified by 'server.access-log.file' or to the app log output,
+// use [SetAccessLogSink] to send entries elsewhere.
+func AccessLogMiddleware() gin.HandlerFunc {
+	sink := newAccessLogSink()
+	sampleRate := GetPropFloat(PropServerAccessLogSampleRate)
+	withHeaders := GetPropBool(PropServerAccessLogHeaders)
+	withBodies := GetPropBool(PropServerAccessLogBodies)
+	maxBodySize := GetPropInt(PropServerAccessLogMaxBodySize)
+	redacted := map[string]struct{}{}
+	for _, h := range GetPropStrSlice(PropServerAccessLogRedactedHeaders) {
+		redacted[http.CanonicalHeaderKey(h)] = struct{}{}
+	}
+
+	return func(c *gin.Context) {
+		start := time.Now()
+		r := c.Request
+
+		var reqBody, resBody *bytes.Buffer
+		if withBodies && maxBodySize > 0 {
+			reqBody = &bytes.Buffer{}
+			if r.Body != nil {
+				// read up to maxBodySize bytes, the body can still be read as a whole by the handler
+				io.CopyN(reqBody, r.Body, int64(maxBodySize))
+				r.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(reqBody.Bytes()), r.Body), Closer: r.Body}
+			}
+			resBody = &bytes.Buffer{}
+			alw := &accessLogResponseWriter{ResponseWriter: c.Writer, buf: resBody, max: maxBodySize}
+			c.Writer = alw
+			c.Set(ctxKeyAccessLogWriter, alw) // for CompressionMiddleware to capture the body before it's compressed
+		}
+
+		c.Next()
+
+		status := c.Writer.Status()
+		rate := sampleRate
+		if v, ok := c.Get(ctxKeyAccessLogSampleRate); ok {
+			rate = v.(float64)
+		}
+		if status < 500 && (rate <= 0 || (rate < 1 && rand.Float64() >= rate)) {
+			return
+		}
+
+		rail := BuildRail(c)
+		user := rail.User()
+		e := AccessLogEntry{
+			Time:      start,
+			Method:    r.Method,
+			Route:     c.FullPath(),
+			Uri:       r.RequestURI,
+			Proto:     r.Proto,
+			Status:    status,
+			LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+			BytesIn:   max(r.ContentLength, 0),
+			BytesOut:  max(c.Writer.Size(), 0),
+			ClientIp:  c.ClientIP(),
+			UserAgent: r.UserAgent(),
+			Referer:   r.Referer(),
+			UserNo:    user.UserNo,
+			Username:  user.Username,
+			TraceId:   rail.TraceId(),
+			SpanId:    rail.SpanId(),
+		}
+		if withHeaders {
+			e.Headers = make(map[string][]string, len(r.Header))
+			for k, v := range r.Header {
+				if _, ok := redacted[http.CanonicalHeaderKey(k)]; ok {
+					v = []string{"***"}
+				}
+				e.Headers[k] = v
+			}
+		}
+		if reqBody != nil {
+			e.RequestBody = reqBody.String()
+			e.ResponseBody = resBody.String()
+		}
+		sink.Log(e)
+	}
+}
+
+func newAccessLogSink() AccessLogSink {
+	accessLogSinkMu.Lock()
+	defer accessLogSinkMu.Unlock()
+	if accessLogSink != nil {
+		return accessLogSink
+	}
+	format := GetPropStr(PropServerAccessLogFormat)
+	var w io.Writer = loggerOut
+	if f := GetPropStr(PropServerAccessLogFile); f != "" {
+		w = BuildRollingLogFileWriter(NewRollingLogFileParam{
+			Filename:   f,
+			MaxSize:    GetPropInt(PropLoggingRollingFileMaxSize), // megabytes
+			MaxAge:     GetPropInt(PropLoggingRollingFileMaxAge),  //days
+			MaxBackups: GetPropInt(PropLoggingRollingFileMaxBackups),
+		})
+	}
+	return NewAccessLogWriterSink(w, format)
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// response writer that keeps a copy of the first max bytes written.
+type accessLogResponseWriter struct {
+	gin.ResponseWriter
+	buf      *bytes.Buffer
+	max      int
+	disabled bool
+}
+
+// capture response body above w instead, e.g., above the compression writer such that the body is not compressed.
+func (w *accessLogResponseWriter) above(rw gin.ResponseWriter) gin.ResponseWriter {
+	w.disabled = true
+	return &accessLogResponseWriter{ResponseWriter: rw, buf: w.buf, max: w.max}
+}
+
+func (w *accessLogResponseWriter) Write(b []byte) (int, error) {
+	w.copy(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *accessLogResponseWriter) WriteString(s string) (int, error) {
+	w.copy([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *accessLogResponseWriter) copy(b []byte) {
+	if w.disabled {
+		return
+	}
+	if n := w.max - w.buf.Len(); n > 0 {
+		w.buf.Write(b[:min(n, len(b))])
+	}
+}
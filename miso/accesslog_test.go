@@ -0,0 +1,128 @@
+package miso
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/curtisnewbie/miso/flow"
+	"github.com/gin-gonic/gin"
+)
+
+func TestAccessLogMiddleware(t *testing.T) {
+	prevRoutes, prevInterceptors := lazyRouteRegistars, interceptors
+	defer func() { lazyRouteRegistars, interceptors = prevRoutes, prevInterceptors }()
+	gin.SetMode(gin.TestMode)
+	SetProp(PropServerAccessLogHeaders, true)
+	SetProp(PropServerAccessLogBodies, true)
+	SetProp(PropServerAccessLogMaxBodySize, 8)
+	defer func() {
+		SetProp(PropServerAccessLogHeaders, false)
+		SetProp(PropServerAccessLogBodies, false)
+		SetProp(PropServerAccessLogMaxBodySize, 4096)
+	}()
+
+	var entries []AccessLogEntry
+	SetAccessLogSink(AccessLogSinkFunc(func(e AccessLogEntry) { entries = append(entries, e) }))
+	defer SetAccessLogSink(nil)
+
+	var received string
+	engine := gin.New()
+	engine.Use(TraceMiddleware(), AccessLogMiddleware())
+	for _, decl := range []*LazyRouteDecl{
+		HttpPost("/order/:id", RawHandler(func(inb *Inbound) {
+			_, r := inb.Unwrap()
+			b, _ := io.ReadAll(r.Body)
+			received = string(b)
+			inb.WriteString("order created")
+		})),
+		HttpGet("/health", RawHandler(func(inb *Inbound) { inb.Status(http.StatusOK) })).AccessLogSampleRate(0),
+		HttpGet("/fail", RawHandler(func(inb *Inbound) { inb.Status(http.StatusBadGateway) })).AccessLogSampleRate(0),
+	} {
+		engine.Handle(decl.Method, decl.Url, decl.Handler)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/order/1?v=2", bytes.NewReader([]byte("1234567890")))
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set(flow.XUserNo, "UE1")
+	req.Header.Set(flow.XTraceId, "test-trace")
+	engine.ServeHTTP(httptest.NewRecorder(), req)
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fail", nil))
+
+	if received != "1234567890" {
+		t.Fatalf("request body is not fully readable by handler: %v", received)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %+v", entries)
+	}
+	e := entries[0]
+	if e.Route != "/order/:id" || e.Uri != "/order/1?v=2" || e.Status != http.StatusOK || e.UserNo != "UE1" || e.TraceId != "test-trace" ||
+		e.BytesIn != 10 || e.BytesOut != 13 || e.RequestBody != "12345678" || e.ResponseBody != "order cr" {
+		t.Fatalf("unexpected entry: %+v", e)
+	}
+	if v := e.Headers["Authorization"]; len(v) != 1 || v[0] != "***" {
+		t.Fatalf("authorization header is not redacted: %v", v)
+	}
+	if entries[1].Status != http.StatusBadGateway {
+		t.Fatalf("5xx response should always be logged: %+v", entries[1])
+	}
+}
+
+func TestAccessLogCompressedBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	SetProp(PropServerAccessLogBodies, true)
+	SetProp(PropServerAccessLogMaxBodySize, 16)
+	SetProp(PropServerCompressionMinSize, 64)
+	SetProp(PropServerCompressionEncodings, []string{EncodingGzip})
+	defer func() {
+		SetProp(PropServerAccessLogBodies, false)
+		SetProp(PropServerAccessLogMaxBodySize, 4096)
+		SetProp(PropServerCompressionMinSize, 1024)
+		SetProp(PropServerCompressionEncodings, []string{EncodingBrotli, EncodingGzip})
+	}()
+
+	var entries []AccessLogEntry
+	SetAccessLogSink(AccessLogSinkFunc(func(e AccessLogEntry) { entries = append(entries, e) }))
+	defer SetAccessLogSink(nil)
+
+	large := strings.Repeat("miso", 100)
+	engine := gin.New()
+	engine.Use(TraceMiddleware(), AccessLogMiddleware(), CompressionMiddleware())
+	engine.GET("/large", func(c *gin.Context) { c.String(http.StatusOK, large) })
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/large", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	engine.ServeHTTP(w, r)
+
+	if w.Header().Get("Content-Encoding") != EncodingGzip {
+		t.Fatalf("response is not compressed: %v", w.Header())
+	}
+	if len(entries) != 1 || entries[0].ResponseBody != large[:16] {
+		t.Fatalf("access log should record the uncompressed body: %+v", entries)
+	}
+}
+
+func TestFormatAccessLogCombined(t *testing.T) {
+	e := AccessLogEntry{
+		Time:      time.Date(2000, 10, 10, 13, 55, 36, 0, time.FixedZone("", -7*3600)),
+		Method:    http.MethodGet,
+		Uri:       "/apache_pb.gif",
+		Proto:     "HTTP/1.0",
+		Status:    200,
+		BytesOut:  2326,
+		ClientIp:  "127.0.0.1",
+		Username:  "frank",
+		Referer:   "http://www.example.com/start.html",
+		UserAgent: "Mozilla/4.08",
+	}
+	exp := `127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326 "http://www.example.com/start.html" "Mozilla/4.08"`
+	if v := FormatAccessLogCombined(e); v != exp {
+		t.Fatalf("unexpected log: %v", v)
+	}
+}
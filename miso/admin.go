@@ -0,0 +1,76 @@
+package miso
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Whether the admin http server is enabled, see 'server.admin.enabled'.
+func IsAdminServerEnabled() bool {
+	return GetPropBool(PropServerEnabled) && GetPropBool(PropServerAdminEnabled)
+}
+
+// Serve the endpoint on the admin http server if 'server.admin.enabled' is true, otherwise it's served as usual.
+//
+// Interceptors registered using [AddInterceptor] are not applied to the endpoints served on the admin http server,
+// these endpoints are protected by 'server.admin.auth.bearer' instead.
+func (g *LazyRouteDecl) Admin() *LazyRouteDecl {
+	g.admin = true
+	return g
+}
+
+// Build endpoint on admin http server, only the route specific interceptors are applied.
+//
+// Routes are prepared as usual except that the authorizer is skipped, see [LazyRouteDecl.prepare].
+func (g *LazyRouteDecl) buildAdmin(engine *gin.Engine) {
+	g.prepare()
+	routeInterceptors := slices.Clone(g.interceptors)
+	handler := g.handler
+	engine.Handle(g.Method, g.Url, func(c *gin.Context) {
+		defer removeUploadTmpFiles(c)
+		newInterceptorChain(c, handler, routeInterceptors).next()
+	})
+}
+
+func newAdminEngine(rail Rail) *gin.Engine {
+	engine := gin.New()
+	engine.Use(TraceMiddleware())
+	engine.Use(gin.RecoveryWithWriter(loggerErrOut, DefaultRecovery))
+
+	if GetPropStrTrimmed(PropServerAdminAuthBearer) != "" {
+		engine.Use(func(c *gin.Context) {
+			// health check requests (e.g., from Consul) don't carry the bearer token
+			if c.Request.Method == http.MethodGet && c.Request.URL.Path == GetPropStr(PropHealthCheckUrl) {
+				c.Next()
+				return
+			}
+			v := GetPropStrTrimmed(PropServerAdminAuthBearer) // prop value may change while it's runs
+			token, ok := ParseBearer(c.GetHeader("Authorization"))
+			if v != "" && (!ok || subtle.ConstantTimeCompare([]byte(token), []byte(v)) != 1) {
+				Debugf("Bearer authorization failed, missing bearer token or token mismatch, %v %v", c.Request.Method, c.Request.RequestURI)
+				c.AbortWithStatus(http.StatusUnauthorized)
+				return
+			}
+			c.Next()
+		})
+		rail.Infof("Using configuration '%v' in authentication interceptor for admin APIs", PropServerAdminAuthBearer)
+	} else if IsProdMode() {
+		rail.Warnf("Admin http server authentication is not enabled in production mode, admin APIs are not protected")
+	}
+	return engine
+}
+
+func startAdminHttpServer(rail Rail, engine *gin.Engine) error {
+	addr := fmt.Sprintf("%s:%s", GetPropStr(PropServerAdminHost), GetPropStr(PropServerAdminPort))
+	rail.Infof("Starting admin HTTP server")
+	port, err := startNetHttpServer(rail, addr, engine, nil)
+	if err != nil {
+		return err
+	}
+	SetProp(PropServerAdminActualPort, port)
+	return nil
+}
@@ -0,0 +1,78 @@
+package miso
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAdminRoute(t *testing.T) {
+	prevRoutes, prevInterceptors := lazyRouteRegistars, interceptors
+	defer func() { lazyRouteRegistars, interceptors = prevRoutes, prevInterceptors }()
+
+	gin.SetMode(gin.TestMode)
+	SetProp(PropServerAdminAuthBearer, "secret")
+	defer SetProp(PropServerAdminAuthBearer, "")
+
+	// global interceptors are not applied to admin endpoints
+	AddInterceptor(func(c *gin.Context, next func()) { c.AbortWithStatus(http.StatusForbidden) })
+
+	admin := HttpGet("/admin", ResHandler(func(inb *Inbound) (string, error) { return "ok", nil })).Admin()
+	biz := HttpGet("/biz", ResHandler(func(inb *Inbound) (string, error) { return "ok", nil }))
+
+	health := HttpGet(GetPropStr(PropHealthCheckUrl), ResHandler(func(inb *Inbound) (string, error) { return "UP", nil })).Admin()
+	limited := HttpPost("/admin/limited", ResHandler(func(inb *Inbound) (string, error) {
+		_, err := inb.ReadRawBytes()
+		return "ok", err
+	})).Admin().MaxBodySize(8)
+
+	adminEngine := newAdminEngine(EmptyRail())
+	admin.buildAdmin(adminEngine)
+	health.buildAdmin(adminEngine)
+	limited.buildAdmin(adminEngine)
+	engine := gin.New()
+	engine.Handle(biz.Method, biz.Url, biz.Handler)
+
+	serve := func(e *gin.Engine, path string, token string) int {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		e.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	if c := serve(adminEngine, "/admin", ""); c != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %v", c)
+	}
+	if c := serve(adminEngine, "/admin", "wrong"); c != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %v", c)
+	}
+	if c := serve(adminEngine, "/admin", "secret"); c != http.StatusOK {
+		t.Fatalf("expected 200, got %v", c)
+	}
+	// health check is not protected by the bearer token
+	if c := serve(adminEngine, GetPropStr(PropHealthCheckUrl), ""); c != http.StatusOK {
+		t.Fatalf("expected 200, got %v", c)
+	}
+
+	// request limits are applied on the admin http server
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/admin/limited", strings.NewReader(strings.Repeat("x", 64)))
+	r.Header.Set("Authorization", "Bearer secret")
+	adminEngine.ServeHTTP(w, r)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413, got %v", w.Code)
+	}
+
+	if c := serve(adminEngine, "/biz", "secret"); c != http.StatusNotFound {
+		t.Fatalf("expected 404, got %v", c)
+	}
+	if c := serve(engine, "/biz", ""); c != http.StatusForbidden {
+		t.Fatalf("expected 403, got %v", c)
+	}
+}
@@ -95,8 +95,13 @@ type HttpRouteDoc struct {
 	Desc                    string           // description of the route (metadata).
 	Scope                   string           // the documented access scope of the route, it maybe "PUBLIC" or something else (metadata).
 	Resource                string           // the documented resource that the route should be bound to (metadata).
+	RateLimit               string           // the documented rate limit of the route (metadata).
 	Headers                 []ParamDoc       // the documented header parameters that will be used by the endpoint (metadata).
 	QueryParams             []ParamDoc       // the documented query parameters that will used by the endpoint (metadata).
+	PathParams              []ParamDoc       // the documented path parameters that will be used by the endpoint (metadata).
+	FormParams              []ParamDoc       // the documented multipart form fields that will be used by the endpoint (metadata).
+	MultipartFiles          []ParamDoc       // the documented files uploaded in multipart form (metadata).
+	WebSocket               bool             // whether the endpoint upgrades the connection to websocket, request and response are the websocket messages (metadata).
 	JsonRequestValue        *reflect.Value   // reflect.Value of json request object
 	JsonRequestDesc         TypeDesc         // the documented json request type that is expected by the endpoint (metadata).
 	JsonResponseValue       *reflect.Value   // reflect.Value of json response object
@@ -69,6 +69,10 @@ func GenMarkDownDoc(hr []HttpRouteDoc, pd []PipelineDoc, opts MarkdownOpt) strin
 			b.WriteString("- Description: ")
 			b.WriteString(r.Desc)
 		}
+		if r.WebSocket {
+			b.WriteRune('\n')
+			b.WriteString("- Protocol: WebSocket")
+		}
 		if r.Scope != "" {
 			b.WriteRune('\n')
 			b.WriteString("- Expected Access Scope: ")
@@ -80,6 +84,11 @@ func GenMarkDownDoc(hr []HttpRouteDoc, pd []PipelineDoc, opts MarkdownOpt) strin
 			b.WriteString(r.Resource)
 			b.WriteString("\"`")
 		}
+		if r.RateLimit != "" {
+			b.WriteRune('\n')
+			b.WriteString("- Rate Limit: ")
+			b.WriteString(r.RateLimit)
+		}
 		if len(r.Headers) > 0 {
 			b.WriteRune('\n')
 			b.WriteString("- Header Parameter:")
@@ -92,6 +101,18 @@ func GenMarkDownDoc(hr []HttpRouteDoc, pd []PipelineDoc, opts MarkdownOpt) strin
 				b.WriteString(h.Desc)
 			}
 		}
+		if len(r.PathParams) > 0 {
+			b.WriteRune('\n')
+			b.WriteString("- Path Parameter:")
+			for _, p := range r.PathParams {
+				b.WriteRune('\n')
+				b.WriteString(strutil.Spaces(2))
+				b.WriteString("- \"")
+				b.WriteString(p.Name)
+				b.WriteString("\": ")
+				b.WriteString(p.Desc)
+			}
+		}
 		if len(r.QueryParams) > 0 {
 			b.WriteRune('\n')
 			b.WriteString("- Query Parameter:")
@@ -104,9 +125,33 @@ func GenMarkDownDoc(hr []HttpRouteDoc, pd []PipelineDoc, opts MarkdownOpt) strin
 				b.WriteString(q.Desc)
 			}
 		}
+		if len(r.FormParams) > 0 || len(r.MultipartFiles) > 0 {
+			b.WriteRune('\n')
+			b.WriteString("- Multipart Form:")
+			for _, p := range r.FormParams {
+				b.WriteRune('\n')
+				b.WriteString(strutil.Spaces(2))
+				b.WriteString("- \"")
+				b.WriteString(p.Name)
+				b.WriteString("\": ")
+				b.WriteString(p.Desc)
+			}
+			for _, p := range r.MultipartFiles {
+				b.WriteRune('\n')
+				b.WriteString(strutil.Spaces(2))
+				b.WriteString("- \"")
+				b.WriteString(p.Name)
+				b.WriteString("\" (file): ")
+				b.WriteString(p.Desc)
+			}
+		}
 		if len(r.JsonRequestDesc.Fields) > 0 {
 			b.WriteRune('\n')
-			b.WriteString("- JSON Request:")
+			if r.WebSocket {
+				b.WriteString("- WebSocket Message (Client to Server):")
+			} else {
+				b.WriteString("- JSON Request:")
+			}
 			if r.JsonRequestDesc.IsSlice {
 				b.WriteString(" (array)")
 			}
@@ -114,7 +159,11 @@ func GenMarkDownDoc(hr []HttpRouteDoc, pd []PipelineDoc, opts MarkdownOpt) strin
 		}
 		if len(r.JsonResponseDesc.Fields) > 0 {
 			b.WriteRune('\n')
-			b.WriteString("- JSON Response:")
+			if r.WebSocket {
+				b.WriteString("- WebSocket Message (Server to Client):")
+			} else {
+				b.WriteString("- JSON Response:")
+			}
 			if r.JsonResponseDesc.IsSlice {
 				b.WriteString(" (array)")
 			}
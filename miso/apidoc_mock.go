@@ -0,0 +1,161 @@
+package miso
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/curtisnewbie/miso/util/hash"
+	"github.com/curtisnewbie/miso/util/json"
+	"github.com/gin-gonic/gin"
+)
+
+// Fixture that overrides the sample response of a mocked route.
+type MockFixture struct {
+	Status int               `json:"status"` // http status, 200 by default
+	Header map[string]string `json:"header"` // response headers
+	Body   any               `json:"body"`   // response body serialized as json; string or []byte is written as is
+}
+
+type MockServerConfig struct {
+	// fixtures keyed by 'METHOD URL', e.g., 'GET /open/api/order/:id', the url is the one declared in HttpRouteDoc.
+	Fixtures map[string]MockFixture
+}
+
+// Mock http server generated from HttpRouteDoc, see [NewMockServer].
+type MockServer struct {
+	engine *gin.Engine
+}
+
+func (m *MockServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.engine.ServeHTTP(w, r)
+}
+
+// Create mock http server that answers every declared route with sample data derived from [TypeDesc] and [FieldDesc].
+//
+// HttpRouteDoc can be collected using misoapi, which also dumps the HttpRouteDoc list as JSON using `-route-doc-json`.
+// The sample responses can be overridden using fixtures, see [MockServerConfig].
+//
+// WebSocket routes are not mocked.
+//
+// E.g.,
+//
+//	ms := miso.NewMockServer(docs, func(c *miso.MockServerConfig) {
+//		c.Fixtures["GET /open/api/order/:id"] = miso.MockFixture{Body: miso.OkRespWData(order)}
+//	})
+//	http.ListenAndServe(":8080", ms)
+func NewMockServer(docs []HttpRouteDoc, options ...func(c *MockServerConfig)) *MockServer {
+	c := MockServerConfig{Fixtures: map[string]MockFixture{}}
+	for _, op := range options {
+		op(&c)
+	}
+
+	engine := gin.New()
+	engine.Use(gin.Recovery())
+	seen := hash.NewSet[string]()
+	for _, d := range docs {
+		if d.WebSocket || d.Method == "" || d.Url == "" {
+			continue
+		}
+		key := mockRouteKey(d.Method, d.Url)
+		if !seen.Add(key) {
+			continue
+		}
+		fixture, ok := c.Fixtures[key]
+		if !ok {
+			fixture = MockFixture{Body: GenMockData(d.JsonResponseDesc)}
+		}
+		engine.Handle(strings.ToUpper(d.Method), d.Url, mockHandler(fixture))
+	}
+	return &MockServer{engine: engine}
+}
+
+func mockRouteKey(method string, url string) string {
+	return strings.ToUpper(method) + " " + url
+}
+
+func mockHandler(f MockFixture) func(c *gin.Context) {
+	status := f.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	var body []byte
+	switch v := f.Body.(type) {
+	case []byte:
+		body = v
+	case string:
+		body = []byte(v)
+	default:
+		b, err := json.WriteJson(v)
+		if err != nil {
+			panic(err)
+		}
+		body = b
+	}
+	return func(c *gin.Context) {
+		for k, v := range f.Header {
+			c.Header(k, v)
+		}
+		c.Data(status, applicationJson, body)
+	}
+}
+
+// Generate sample data conforming to the TypeDesc.
+//
+// Slices contain one sample element, fields without known types are null.
+func GenMockData(d TypeDesc) any {
+	if len(d.Fields) < 1 {
+		if d.TypeName == "" {
+			return OkResp()
+		}
+		return mockValue(d.TypeName, d.TypeName)
+	}
+	m := genMockMap(d.Fields)
+	if d.IsSlice {
+		return []any{m}
+	}
+	return m
+}
+
+func genMockMap(descs []FieldDesc) map[string]any {
+	m := make(map[string]any, len(descs))
+	for _, d := range descs {
+		if d.JsonName == "" || d.JsonName == "-" {
+			continue
+		}
+		if len(d.Fields) > 0 {
+			v := genMockMap(d.Fields)
+			if d.IsSliceOrArray {
+				m[d.JsonName] = []any{v}
+			} else {
+				m[d.JsonName] = v
+			}
+			continue
+		}
+		m[d.JsonName] = mockValue(d.TypeNameAlias, d.OriginTypeName)
+	}
+	return m
+}
+
+func mockValue(typeName string, originTypeName string) any {
+	typeName = strings.TrimPrefix(strings.TrimSpace(typeName), "*")
+	if v, ok := strings.CutPrefix(typeName, "[]"); ok {
+		if e := mockValue(v, v); e != nil {
+			return []any{e}
+		}
+		return []any{}
+	}
+	switch typeName {
+	case "string":
+		return "string"
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+		if originTypeName == "Time" || originTypeName == "*Time" || strings.HasSuffix(originTypeName, ".Time") {
+			return 1768184753983 // epochmilli
+		}
+		return 0
+	case "float32", "float64":
+		return 0.0
+	case "bool":
+		return false
+	}
+	return nil
+}
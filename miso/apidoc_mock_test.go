@@ -0,0 +1,75 @@
+package miso
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/curtisnewbie/miso/util/json"
+)
+
+func TestMockServer(t *testing.T) {
+	resp := TypeDesc{
+		TypeName: "Resp",
+		Fields: []FieldDesc{
+			{JsonName: "errorCode", TypeNameAlias: "string", OriginTypeName: "string"},
+			{JsonName: "error", TypeNameAlias: "bool", OriginTypeName: "bool"},
+			{JsonName: "data", TypeNameAlias: "Order", OriginTypeName: "Order", Fields: []FieldDesc{
+				{JsonName: "id", TypeNameAlias: "int64", OriginTypeName: "int64"},
+				{JsonName: "tags", TypeNameAlias: "[]string", OriginTypeName: "[]string"},
+				{JsonName: "createdAt", TypeNameAlias: "int64", OriginTypeName: "Time"},
+				{JsonName: "items", TypeNameAlias: "[]Item", OriginTypeName: "[]Item", IsSliceOrArray: true, Fields: []FieldDesc{
+					{JsonName: "price", TypeNameAlias: "float64", OriginTypeName: "float64"},
+				}},
+			}},
+		},
+	}
+	docs := []HttpRouteDoc{
+		{Method: http.MethodGet, Url: "/open/api/order/:id", JsonResponseDesc: resp},
+		{Method: http.MethodPost, Url: "/open/api/order", JsonResponseDesc: resp},
+		{Method: http.MethodGet, Url: "/ws", WebSocket: true},
+	}
+
+	// dumped HttpRouteDoc list can be loaded back
+	buf, err := json.WriteJson(docs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	docs = nil
+	if err := json.ParseJson(buf, &docs); err != nil {
+		t.Fatal(err)
+	}
+
+	ms := NewMockServer(docs, func(c *MockServerConfig) {
+		c.Fixtures["POST /open/api/order"] = MockFixture{Status: http.StatusConflict, Body: ErrorRespWCode("DUPLICATE_ORDER", "Duplicate order")}
+	})
+	call := func(method string, url string) (int, map[string]any) {
+		w := httptest.NewRecorder()
+		ms.ServeHTTP(w, httptest.NewRequest(method, url, nil))
+		var m map[string]any
+		if w.Code != http.StatusNotFound {
+			if err := json.ParseJson(w.Body.Bytes(), &m); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return w.Code, m
+	}
+
+	code, body := call(http.MethodGet, "/open/api/order/123")
+	if code != http.StatusOK {
+		t.Fatalf("unexpected status: %v", code)
+	}
+	exp := `{"data":{"createdAt":1768184753983,"id":0,"items":[{"price":0}],"tags":["string"]},"error":false,"errorCode":"string"}`
+	if s, _ := json.CustomSWriteJson(apiDocJsoniterConfig, body); s != exp {
+		t.Fatalf("unexpected body: %v", s)
+	}
+
+	code, body = call(http.MethodPost, "/open/api/order")
+	if code != http.StatusConflict || body["errorCode"] != "DUPLICATE_ORDER" {
+		t.Fatalf("unexpected response: %v, %v", code, body)
+	}
+
+	if code, _ = call(http.MethodGet, "/ws"); code != http.StatusNotFound {
+		t.Fatalf("websocket route should not be mocked, %v", code)
+	}
+}
@@ -1,6 +1,10 @@
 package miso
 
 import (
+	"net/http"
+	"slices"
+	"strings"
+
 	"github.com/curtisnewbie/miso/util/json"
 	"github.com/getkin/kin-openapi/openapi3"
 )
@@ -22,6 +26,20 @@ func GenOpenApiDoc(d HttpRouteDoc, root *openapi3.T, server string) string {
 		Description: d.Desc,
 	}
 
+	for _, v := range openApiPathParams(d) {
+		op.AddParameter(&openapi3.Parameter{
+			Name:        v.Name,
+			In:          "path",
+			Required:    true,
+			Description: v.Desc,
+			Schema: &openapi3.SchemaRef{
+				Value: &openapi3.Schema{
+					Type: &openapi3.Types{"string"},
+				},
+			},
+		})
+	}
+
 	for _, v := range d.QueryParams {
 		op.AddParameter(&openapi3.Parameter{
 			Name:        v.Name,
@@ -50,7 +68,7 @@ func GenOpenApiDoc(d HttpRouteDoc, root *openapi3.T, server string) string {
 		})
 	}
 
-	if d.JsonRequestValue != nil {
+	if d.JsonRequestValue != nil && !d.WebSocket {
 		if p := d.JsonRequestDesc.toOpenApiReq(d.JsonReqGoDefTypeName); p != nil {
 			op.RequestBody = &openapi3.RequestBodyRef{}
 			op.RequestBody.Value = &openapi3.RequestBody{}
@@ -58,10 +76,35 @@ func GenOpenApiDoc(d HttpRouteDoc, root *openapi3.T, server string) string {
 		}
 	}
 
-	if p := d.JsonResponseDesc.toOpenApiResp(d.JsonRespGoDefTypeName); p != nil {
+	if len(d.FormParams) > 0 || len(d.MultipartFiles) > 0 {
+		schema := openapi3.NewObjectSchema()
+		for _, v := range d.FormParams {
+			schema.WithPropertyRef(v.Name, &openapi3.SchemaRef{Value: &openapi3.Schema{Type: &openapi3.Types{"string"}, Description: v.Desc}})
+		}
+		for _, v := range d.MultipartFiles {
+			schema.WithPropertyRef(v.Name, &openapi3.SchemaRef{Value: &openapi3.Schema{Type: &openapi3.Types{"string"}, Format: "binary", Description: v.Desc}})
+		}
+		op.RequestBody = &openapi3.RequestBodyRef{
+			Value: openapi3.NewRequestBody().WithContent(openapi3.NewContentWithFormDataSchema(schema)),
+		}
+	}
+
+	if d.WebSocket {
+		// websocket messages are not describable in openapi, only the upgrade is documented
+		op.Extensions = map[string]any{"x-websocket": true}
+		op.AddResponse(http.StatusSwitchingProtocols, openapi3.NewResponse().WithDescription("Switching Protocols, messages are exchanged over websocket"))
+	} else if p := d.JsonResponseDesc.toOpenApiResp(d.JsonRespGoDefTypeName); p != nil {
 		op.AddResponse(200, p)
 	}
 
+	if d.RateLimit != "" {
+		if op.Extensions == nil {
+			op.Extensions = map[string]any{}
+		}
+		op.Extensions["x-rate-limit"] = d.RateLimit
+		op.AddResponse(http.StatusTooManyRequests, openapi3.NewResponse().WithDescription("Too Many Requests, rate limit: "+d.RateLimit))
+	}
+
 	doc := openapi3.T{
 		OpenAPI: "3.0.0",
 		Info: &openapi3.Info{
@@ -70,12 +113,44 @@ func GenOpenApiDoc(d HttpRouteDoc, root *openapi3.T, server string) string {
 		},
 		Servers: servers,
 	}
-	doc.AddOperation(d.Url, d.Method, op)
+	path := openApiPath(d.Url)
+	doc.AddOperation(path, d.Method, op)
 
 	if root != nil {
-		root.AddOperation(d.Url, d.Method, op)
+		root.AddOperation(path, d.Method, op)
 	}
 
 	j, _ := json.SWriteJson(doc)
 	return j
 }
+
+// Convert gin path parameters to OpenAPI path template, e.g., '/user/:id' to '/user/{id}'.
+func openApiPath(url string) string {
+	segs := strings.Split(url, "/")
+	for i, seg := range segs {
+		if v, ok := strings.CutPrefix(seg, ":"); ok {
+			segs[i] = "{" + v + "}"
+		} else if v, ok := strings.CutPrefix(seg, "*"); ok {
+			segs[i] = "{" + v + "}"
+		}
+	}
+	return strings.Join(segs, "/")
+}
+
+// Documented path parameters, parameters in the url that are not documented are also included.
+func openApiPathParams(d HttpRouteDoc) []ParamDoc {
+	params := slices.Clone(d.PathParams)
+	for _, seg := range strings.Split(d.Url, "/") {
+		name, ok := strings.CutPrefix(seg, ":")
+		if !ok {
+			name, ok = strings.CutPrefix(seg, "*")
+		}
+		if !ok || name == "" {
+			continue
+		}
+		if !slices.ContainsFunc(params, func(p ParamDoc) bool { return p.Name == name }) {
+			params = append(params, ParamDoc{Name: name})
+		}
+	}
+	return params
+}
@@ -0,0 +1,21 @@
+package miso
+
+import "testing"
+
+func TestOpenApiPath(t *testing.T) {
+	cases := map[string]string{
+		"/api/user":              "/api/user",
+		"/api/user/:id":          "/api/user/{id}",
+		"/api/user/:id/file/*fp": "/api/user/{id}/file/{fp}",
+	}
+	for url, expected := range cases {
+		if v := openApiPath(url); v != expected {
+			t.Errorf("url: '%v', expected '%v', got '%v'", url, expected, v)
+		}
+	}
+
+	params := openApiPathParams(HttpRouteDoc{Url: "/api/user/:id/file/*fp", PathParams: []ParamDoc{{Name: "id", Desc: "user id"}}})
+	if len(params) != 2 || params[0].Desc != "user id" || params[1].Name != "fp" {
+		t.Fatalf("unexpected path params: %+v", params)
+	}
+}
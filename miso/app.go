@@ -215,6 +215,9 @@ func (a *MisoApp) Bootstrap(args []string) {
 		rail.Infof("OnAppReady finished, took: %v", time.Since(start))
 	}
 
+	// restarted gracefully, the previous process can now shutdown
+	notifyRestartReady(rail)
+
 	end := time.Now().UnixMilli()
 	split = strings.Repeat("-", 52)
 	rail.Infof("\n\n%s %s started (took: %dms) %s\n", split, appName, end-start, split)
@@ -0,0 +1,127 @@
+package miso
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/curtisnewbie/miso/errs"
+	"github.com/curtisnewbie/miso/flow"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	ErrCodeUnauthorized = "UNAUTHORIZED"
+	ErrCodeForbidden    = "FORBIDDEN"
+)
+
+var (
+	ErrUnauthorized = errs.NewErrfCode(ErrCodeUnauthorized, "Unauthorized").
+			WithHttpStatus(http.StatusUnauthorized)
+	ErrForbidden = errs.NewErrfCode(ErrCodeForbidden, "Forbidden").
+			WithHttpStatus(http.StatusForbidden)
+)
+
+var (
+	permissionChecker   PermissionChecker
+	permissionCache     TTLCache[bool]
+	permissionCacheOnce sync.Once
+)
+
+// Checks whether the user has access to the resource.
+//
+// PermissionChecker is used by the in-process authorizer to enforce routes bound to resources, see [LazyRouteDecl.Resource].
+type PermissionChecker interface {
+	HasPermission(rail Rail, user flow.User, resource string) (bool, error)
+}
+
+// Func that implements [PermissionChecker].
+type PermissionCheckerFunc func(rail Rail, user flow.User, resource string) (bool, error)
+
+func (f PermissionCheckerFunc) HasPermission(rail Rail, user flow.User, resource string) (bool, error) {
+	return f(rail, user, resource)
+}
+
+// Set PermissionChecker used by the in-process authorizer.
+//
+// The authorizer is only enabled when 'server.authorization.enabled' is true.
+func SetPermissionChecker(pc PermissionChecker) {
+	permissionChecker = pc
+}
+
+// Create interceptor that authorizes requests based on route's Scope and Resource.
+//
+// Routes without Scope are treated as [ScopeProtected], only routes explicitly marked [ScopePublic] (without Resource) and
+// admin routes served on the admin http server (see [LazyRouteDecl.Admin]) are not checked. For other routes, the user on the Rail is required, otherwise
+// [ErrUnauthorized] is returned. If the route is bound to a resource, the user must have permission to the resource
+// (checked using [PermissionChecker]), otherwise [ErrForbidden] is returned.
+func (g *LazyRouteDecl) authorizer() (func(c *gin.Context, next func()), bool) {
+	var scope, resource string
+	for _, ex := range g.Extras {
+		switch ex.Left {
+		case ExtraScope:
+			if v, ok := ex.Right.(string); ok {
+				scope = v
+			}
+		case ExtraResource:
+			if v, ok := ex.Right.(string); ok {
+				resource = v
+			}
+		}
+	}
+	if scope == "" {
+		scope = ScopeProtected
+	}
+	// admin routes are protected by 'server.admin.auth.bearer' if they are served on the admin http server
+	if (g.admin && IsAdminServerEnabled()) || (resource == "" && scope == ScopePublic) {
+		return nil, false
+	}
+	if resource != "" && permissionChecker == nil {
+		Warnf("PermissionChecker is not set, requests to %v %v are always forbidden", g.Method, g.Url)
+	}
+
+	return func(c *gin.Context, next func()) {
+		inb := newInbound(c)
+		rail := inb.Rail()
+		user := rail.User()
+		if user.IsNil || user.UserNo == "" {
+			rail.Debugf("Request unauthorized, missing user, %v %v", g.Method, g.Url)
+			inb.HandleResult(nil, ErrUnauthorized.New())
+			return
+		}
+		if resource != "" {
+			ok, err := checkPermission(rail, user, resource)
+			if err != nil {
+				inb.HandleResult(nil, err)
+				return
+			}
+			if !ok {
+				rail.Debugf("Request forbidden, user %v has no permission to resource '%v', %v %v", user.UserNo, resource, g.Method, g.Url)
+				inb.HandleResult(nil, ErrForbidden.New())
+				return
+			}
+		}
+		next()
+	}, true
+}
+
+func checkPermission(rail Rail, user flow.User, resource string) (bool, error) {
+	pc := permissionChecker
+	if pc == nil {
+		return false, nil
+	}
+	permissionCacheOnce.Do(func() {
+		permissionCache = NewTTLCache[bool](GetPropDuration(PropServerAuthorizationCacheTtl),
+			GetPropInt(PropServerAuthorizationCacheSize))
+	})
+
+	key := user.UserNo + "|" + user.RoleNo + "|" + resource
+	if ok, hit := permissionCache.TryGet(key); hit {
+		return ok, nil
+	}
+	ok, err := pc.HasPermission(rail, user, resource)
+	if err != nil {
+		return false, errs.Wrapf(err, "failed to check permission, user: %v, resource: %v", user.UserNo, resource)
+	}
+	permissionCache.Put(key, ok)
+	return ok, nil
+}
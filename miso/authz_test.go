@@ -0,0 +1,80 @@
+package miso
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/curtisnewbie/miso/flow"
+	"github.com/gin-gonic/gin"
+)
+
+func TestAuthorizer(t *testing.T) {
+	prevRoutes, prevInterceptors := lazyRouteRegistars, interceptors
+	defer func() { lazyRouteRegistars, interceptors = prevRoutes, prevInterceptors }()
+	gin.SetMode(gin.TestMode)
+	SetProp(PropServerAuthorizationEnabled, true)
+	defer SetProp(PropServerAuthorizationEnabled, false)
+
+	checked := 0
+	SetPermissionChecker(PermissionCheckerFunc(func(rail Rail, user flow.User, resource string) (bool, error) {
+		checked++
+		return user.RoleNo == "admin" && resource == "manage-users", nil
+	}))
+	defer SetPermissionChecker(nil)
+
+	handler := RawHandler(func(inb *Inbound) { inb.Status(http.StatusOK) })
+	engine := gin.New()
+	engine.Use(TraceMiddleware())
+	for _, decl := range []*LazyRouteDecl{
+		HttpGet("/public", handler).Public(),
+		HttpGet("/none", handler),
+		HttpGet("/admin", handler).Admin(),
+		HttpGet("/protected", handler).Protected(),
+		HttpGet("/users", handler).Resource("manage-users"),
+	} {
+		decl.build(engine)
+	}
+
+	// admin routes are only exempted when they are served on the admin http server
+	SetProp(PropServerAdminEnabled, true)
+	HttpGet("/admin-server", handler).Admin().build(engine)
+	SetProp(PropServerAdminEnabled, false)
+
+	call := func(url string, userNo string, roleNo string) int {
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		if userNo != "" {
+			req.Header.Set(flow.XUserNo, userNo)
+			req.Header.Set(flow.XRoleNo, roleNo)
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	cases := []struct {
+		url, userNo, roleNo string
+		expected            int
+	}{
+		{"/public", "", "", http.StatusOK},
+		{"/none", "", "", http.StatusUnauthorized},
+		{"/none", "UE1", "guest", http.StatusOK},
+		{"/admin", "", "", http.StatusUnauthorized},
+		{"/admin", "UE1", "guest", http.StatusOK},
+		{"/admin-server", "", "", http.StatusOK},
+		{"/protected", "", "", http.StatusUnauthorized},
+		{"/protected", "UE1", "guest", http.StatusOK},
+		{"/users", "", "", http.StatusUnauthorized},
+		{"/users", "UE1", "guest", http.StatusForbidden},
+		{"/users", "UE2", "admin", http.StatusOK},
+		{"/users", "UE2", "admin", http.StatusOK},
+	}
+	for _, c := range cases {
+		if v := call(c.url, c.userNo, c.roleNo); v != c.expected {
+			t.Fatalf("%v, user: %v, role: %v, expected %v, got %v", c.url, c.userNo, c.roleNo, c.expected, v)
+		}
+	}
+	if checked != 2 {
+		t.Fatalf("decisions are not cached, checked: %v", checked)
+	}
+}
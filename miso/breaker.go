@@ -0,0 +1,332 @@
+package miso
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/curtisnewbie/miso/errs"
+	"github.com/curtisnewbie/miso/util/hash"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	ErrCodeCircuitOpen = "CIRCUIT_OPEN"
+
+	CircuitClosed   = "CLOSED"
+	CircuitOpen     = "OPEN"
+	CircuitHalfOpen = "HALF_OPEN"
+
+	circuitBreakerBuckets = 10
+)
+
+var (
+	ErrCircuitOpen = errs.NewErrfCode(ErrCodeCircuitOpen, "Service is temporarily unavailable, please try again later").
+			WithHttpStatus(http.StatusServiceUnavailable)
+
+	// circuit breakers keyed by service name or 'service/host:port'.
+	circuitBreakers = hash.NewStrRWMap[*circuitBreaker]()
+
+	circuitBreakerGauge = sync.OnceValue(func() *prometheus.GaugeVec {
+		return NewPromGaugeVec("miso_client_circuit_breaker_state", []string{"service", "instance"})
+	})
+)
+
+func init() {
+	RegisterBootstrapCallback(ComponentBootstrap{
+		Name: "Bootstrap Circuit Breaker HealthIndicator",
+		Condition: func(rail Rail) (bool, error) {
+			return GetPropBool(PropClientCircuitBreakerEnabled) && GetPropBool(PropClientCircuitBreakerHealthIndicator), nil
+		},
+		Bootstrap: func(rail Rail) error {
+			AddHealthIndicator(HealthIndicator{
+				Name: "Circuit Breaker",
+				CheckHealth: func(rail Rail) bool {
+					for _, s := range CircuitBreakerStates() {
+						if s.Instance == "" && s.State == CircuitOpen {
+							rail.Warnf("Circuit of service %v is open", s.Service)
+							return false
+						}
+					}
+					return true
+				},
+			})
+			return nil
+		},
+	})
+}
+
+// State of circuit breaker.
+type CircuitBreakerState struct {
+	Service  string
+	Instance string // 'host:port' of the instance, empty for the service level circuit breaker
+	State    string // [CircuitClosed], [CircuitOpen] or [CircuitHalfOpen]
+}
+
+// List states of all circuit breakers, see 'client.circuit-breaker.enabled'.
+func CircuitBreakerStates() []CircuitBreakerState {
+	keys := circuitBreakers.Keys()
+	sort.Strings(keys)
+	states := make([]CircuitBreakerState, 0, len(keys))
+	for _, k := range keys {
+		if cb, ok := circuitBreakers.Get(k); ok {
+			states = append(states, CircuitBreakerState{Service: cb.service, Instance: cb.instance, State: cb.currentState()})
+		}
+	}
+	return states
+}
+
+type circuitBucket struct {
+	epoch  int64
+	total  int
+	failed int
+}
+
+type circuitBreaker struct {
+	mu       sync.Mutex
+	service  string
+	instance string
+
+	consecutiveFailures int
+	failureRate         float64
+	minRequests         int
+	bucketSize          time.Duration
+	openDuration        time.Duration
+	halfOpenRequests    int
+
+	state       string
+	openedAt    time.Time
+	consecutive int
+	probes      int
+	buckets     [circuitBreakerBuckets]circuitBucket
+}
+
+func newCircuitBreaker(service string, instance string) *circuitBreaker {
+	cb := &circuitBreaker{
+		service:             service,
+		instance:            instance,
+		consecutiveFailures: GetPropInt(PropClientCircuitBreakerConsecutiveFailures),
+		failureRate:         GetPropFloat(PropClientCircuitBreakerFailureRate),
+		minRequests:         GetPropInt(PropClientCircuitBreakerMinRequests),
+		bucketSize:          max(GetPropDuration(PropClientCircuitBreakerWindow)/circuitBreakerBuckets, time.Millisecond),
+		openDuration:        GetPropDuration(PropClientCircuitBreakerOpenDuration),
+		halfOpenRequests:    max(GetPropInt(PropClientCircuitBreakerHalfOpenRequests), 1),
+		state:               CircuitClosed,
+	}
+	cb.updateGauge()
+	return cb
+}
+
+func getCircuitBreaker(service string, instance string) *circuitBreaker {
+	key := service
+	if instance != "" {
+		key += "/" + instance
+	}
+	created := false
+	cb, _ := circuitBreakers.GetElse(key, func(k string) *circuitBreaker {
+		created = true
+		return newCircuitBreaker(service, instance)
+	})
+	if created && instance != "" {
+		discModule().watchServerList("circuit-breaker", service, func(servers []Server) {
+			evictCircuitBreakers(service, servers)
+		})
+	}
+	return cb
+}
+
+// evict circuit breakers of instances that are no longer in the server list.
+func evictCircuitBreakers(service string, servers []Server) {
+	for _, k := range circuitBreakers.Keys() {
+		cb, ok := circuitBreakers.Get(k)
+		if !ok || cb.service != service || cb.instance == "" {
+			continue
+		}
+		if slices.ContainsFunc(servers, func(s Server) bool { return s.ServerAddress() == cb.instance }) {
+			continue
+		}
+		circuitBreakers.Del(k)
+		circuitBreakerGauge().DeleteLabelValues(cb.service, cb.instance)
+		Debugf("Evicted circuit breaker of instance %v, service %v", cb.instance, cb.service)
+	}
+}
+
+// state with the open to half-open transition evaluated.
+func (cb *circuitBreaker) currentState() string {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if cb.state == CircuitOpen && time.Since(cb.openedAt) >= cb.openDuration {
+		return CircuitHalfOpen
+	}
+	return cb.state
+}
+
+func (cb *circuitBreaker) allow() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	switch cb.state {
+	case CircuitOpen:
+		if time.Since(cb.openedAt) < cb.openDuration {
+			return false
+		}
+		cb.transit(CircuitHalfOpen)
+		fallthrough
+	case CircuitHalfOpen:
+		if cb.probes >= cb.halfOpenRequests {
+			return false
+		}
+		cb.probes++
+	}
+	return true
+}
+
+// release permit that is not used.
+func (cb *circuitBreaker) release() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if cb.state == CircuitHalfOpen && cb.probes > 0 {
+		cb.probes--
+	}
+}
+
+func (cb *circuitBreaker) record(failed bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case CircuitOpen:
+		return // requests sent before the circuit is opened
+	case CircuitHalfOpen:
+		if cb.probes > 0 {
+			cb.probes--
+		}
+		if failed {
+			cb.transit(CircuitOpen)
+		} else {
+			cb.transit(CircuitClosed)
+		}
+		return
+	}
+
+	epoch := time.Now().UnixNano() / int64(cb.bucketSize)
+	b := &cb.buckets[epoch%circuitBreakerBuckets]
+	if b.epoch != epoch {
+		*b = circuitBucket{epoch: epoch}
+	}
+	b.total++
+	if !failed {
+		cb.consecutive = 0
+		return
+	}
+	b.failed++
+	cb.consecutive++
+
+	if cb.consecutiveFailures > 0 && cb.consecutive >= cb.consecutiveFailures {
+		cb.transit(CircuitOpen)
+		return
+	}
+	if cb.failureRate > 0 {
+		var total, failures int
+		for _, b := range cb.buckets {
+			if b.epoch > epoch-circuitBreakerBuckets {
+				total += b.total
+				failures += b.failed
+			}
+		}
+		if total >= cb.minRequests && float64(failures)/float64(total) >= cb.failureRate {
+			cb.transit(CircuitOpen)
+		}
+	}
+}
+
+func (cb *circuitBreaker) transit(state string) {
+	if cb.state == state {
+		return
+	}
+	Warnf("Circuit of service %v %v transits from %v to %v", cb.service, cb.instance, cb.state, state)
+	cb.state = state
+	cb.probes = 0
+	switch state {
+	case CircuitOpen:
+		cb.openedAt = time.Now()
+	case CircuitClosed:
+		cb.consecutive = 0
+		cb.buckets = [circuitBreakerBuckets]circuitBucket{}
+	}
+	cb.updateGauge()
+}
+
+func (cb *circuitBreaker) updateGauge() {
+	var v float64
+	switch cb.state {
+	case CircuitOpen:
+		v = 1
+	case CircuitHalfOpen:
+		v = 2
+	}
+	circuitBreakerGauge().WithLabelValues(cb.service, cb.instance).Set(v)
+}
+
+func circuitBreakerEnabled() bool {
+	return GetPropBool(PropClientCircuitBreakerEnabled)
+}
+
+// filter out instances whose circuit is open.
+func filterOpenCircuits(service string, servers []Server) ([]Server, error) {
+	if !circuitBreakerEnabled() {
+		return servers, nil
+	}
+	filtered := make([]Server, 0, len(servers))
+	for _, s := range servers {
+		if getCircuitBreaker(service, s.ServerAddress()).currentState() != CircuitOpen {
+			filtered = append(filtered, s)
+		}
+	}
+	if len(filtered) < 1 {
+		return nil, ErrCircuitOpen.WithInternalMsg("circuits of all instances of service %v are open", service)
+	}
+	return filtered, nil
+}
+
+// send request with circuit breakers of the service and the instance applied, in-flight requests are recorded for LoadBalancer,
+// and responses are recorded for outlier detection.
+func (t *Client) roundTrip(req *http.Request) (*http.Response, error) {
+	if !t.discoverService {
+		return t.client.Do(req)
+	}
+	defer lbRequestStarted(req.URL.Host)()
+
+	var scb, icb *circuitBreaker
+	if circuitBreakerEnabled() {
+		scb = getCircuitBreaker(t.serviceName, "")
+		if !scb.allow() {
+			return nil, ErrCircuitOpen.WithInternalMsg("circuit of service %v is open", t.serviceName)
+		}
+		icb = getCircuitBreaker(t.serviceName, req.URL.Host)
+		if !icb.allow() {
+			scb.release()
+			return nil, ErrCircuitOpen.WithInternalMsg("circuit of service %v instance %v is open", t.serviceName, req.URL.Host)
+		}
+	}
+
+	start := time.Now()
+	r, err := t.client.Do(req)
+	if err != nil && errors.Is(err, context.Canceled) {
+		if scb != nil {
+			scb.release() // cancelled by caller, not a failure of the service
+			icb.release()
+		}
+		return r, err
+	}
+	failed := err != nil || r.StatusCode >= 500 && r.StatusCode != http.StatusNotImplemented
+	if scb != nil {
+		scb.record(failed)
+		icb.record(failed)
+	}
+	recordOutlier(t.serviceName, req.URL.Host, failed, time.Since(start))
+	return r, err
+}
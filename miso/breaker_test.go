@@ -0,0 +1,109 @@
+package miso
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/curtisnewbie/miso/errs"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	SetProp("client.addr.breaker-service.host", "10.0.0.2")
+	SetProp("client.addr.breaker-service.port", 8080)
+	SetProp(PropClientCircuitBreakerEnabled, true)
+	SetProp(PropClientCircuitBreakerConsecutiveFailures, 2)
+	SetProp(PropClientCircuitBreakerOpenDuration, "50ms")
+	defer func() {
+		SetProp(PropClientCircuitBreakerEnabled, false)
+		SetProp(PropClientCircuitBreakerConsecutiveFailures, 5)
+		SetProp(PropClientCircuitBreakerOpenDuration, "30s")
+		for _, k := range circuitBreakers.Keys() {
+			circuitBreakers.Del(k)
+		}
+	}()
+
+	calls := 0
+	status := http.StatusServiceUnavailable
+	send := func() *TResponse {
+		return NewClient(EmptyRail(), "lb://breaker-service/api").UseClient(&http.Client{
+			Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				calls++
+				return &http.Response{StatusCode: status, Body: io.NopCloser(bytes.NewReader(nil)), Header: http.Header{}}, nil
+			}),
+		}).Get()
+	}
+
+	send().Close()
+	send().Close()
+	if calls != 2 {
+		t.Fatalf("unexpected calls: %v", calls)
+	}
+	states := CircuitBreakerStates()
+	if len(states) != 2 || states[0].State != CircuitOpen || states[1].Instance != "10.0.0.2:8080" || states[1].State != CircuitOpen {
+		t.Fatalf("unexpected states: %+v", states)
+	}
+
+	// fail fast
+	resp := send()
+	resp.Close()
+	if calls != 2 || !errs.IsAny(resp.Err, ErrCircuitOpen) {
+		t.Fatalf("request is not rejected, calls: %v, err: %v", calls, resp.Err)
+	}
+
+	// probe in half-open state
+	time.Sleep(60 * time.Millisecond)
+	status = http.StatusOK
+	resp = send()
+	resp.Close()
+	if calls != 3 || resp.Err != nil {
+		t.Fatalf("probe request is not sent, calls: %v, err: %v", calls, resp.Err)
+	}
+	for _, s := range CircuitBreakerStates() {
+		if s.State != CircuitClosed {
+			t.Fatalf("circuit is not closed: %+v", s)
+		}
+	}
+}
+
+func TestCircuitBreakerEviction(t *testing.T) {
+	m := discModule()
+	prev := m.getServerList
+	servers := []Server{{Address: "10.0.0.1", Port: 8080}, {Address: "10.0.0.2", Port: 8080}}
+	ChangeGetServerList(func() ServerList { return staticServerList{servers: servers} })
+	defer func() {
+		ChangeGetServerList(prev)
+		for _, k := range circuitBreakers.Keys() {
+			circuitBreakers.Del(k)
+		}
+	}()
+
+	getCircuitBreaker("evict-service", "")
+	getCircuitBreaker("evict-service", "10.0.0.1:8080")
+	getCircuitBreaker("evict-service", "10.0.0.2:8080")
+
+	servers = servers[1:]
+	TriggerServerChangeListeners("evict-service")
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, ok := circuitBreakers.Get("evict-service/10.0.0.1:8080"); !ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("circuit breaker of removed instance is not evicted")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if _, ok := circuitBreakers.Get("evict-service/10.0.0.2:8080"); !ok {
+		t.Fatal("circuit breaker of remaining instance is evicted")
+	}
+	if _, ok := circuitBreakers.Get("evict-service"); !ok {
+		t.Fatal("service level circuit breaker is evicted")
+	}
+	if circuitBreakerGauge().DeleteLabelValues("evict-service", "10.0.0.1:8080") {
+		t.Fatal("gauge of removed instance is not deleted")
+	}
+}
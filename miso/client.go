@@ -207,6 +207,8 @@ func (tr *TResponse) logRespBody(body any) {
 // If response body is somehow empty, *miso.NoneErr is returned.
 //
 // If ptr impl [TResponseJsonCheckErr], [TResponseJsonCheckErr.CheckErr] is called after json unmarshalling.
+//
+// If the response is RFC 7807 problem details (`application/problem+json`), it's parsed as *MisoErr and returned.
 func (tr *TResponse) Json(ptr any) error {
 	defer tr.Close()
 	if tr.Err != nil {
@@ -222,6 +224,10 @@ func (tr *TResponse) Json(ptr any) error {
 	}
 	tr.logRespBody(body)
 
+	if isProblemResp(tr.Resp) {
+		return parseProblemErr(body)
+	}
+
 	if e = json.ParseJson(body, ptr); e != nil {
 		s := strutil.UnsafeByt2Str(body)
 		return errs.Wrapf(e, "failed to unmarshal json from response, body: %v", s)
@@ -243,6 +249,8 @@ func (tr *TResponse) Json(ptr any) error {
 // If response body is somehow empty, *miso.NoneErr is returned.
 //
 // If ptr impl [TResponseJsonCheckErr], [TResponseJsonCheckErr.CheckErr] is called after json unmarshalling.
+//
+// If the response is RFC 7807 problem details (`application/problem+json`), it's parsed as *MisoErr and returned.
 func (tr *TResponse) JsonStr(ptr any) (_originalJson string, _err error) {
 	defer tr.Close()
 	if tr.Err != nil {
@@ -258,6 +266,10 @@ func (tr *TResponse) JsonStr(ptr any) (_originalJson string, _err error) {
 	}
 	tr.logRespBody(body)
 
+	if isProblemResp(tr.Resp) {
+		return string(body), parseProblemErr(body)
+	}
+
 	if e = json.ParseJson(body, ptr); e != nil {
 		return "", errs.Wrapf(e, "failed to unmarshal json from response, body: %v", strutil.UnsafeByt2Str(body))
 	}
@@ -271,6 +283,71 @@ func (tr *TResponse) JsonStr(ptr any) (_originalJson string, _err error) {
 	return string(body), nil
 }
 
+// Read response using the Codec matching the response 'Content-Type', JSON is used if none matches.
+//
+// Response is always closed automatically.
+//
+// If response body is somehow empty, *miso.NoneErr is returned.
+//
+// If ptr impl [TResponseJsonCheckErr], [TResponseJsonCheckErr.CheckErr] is called after unmarshalling.
+func (tr *TResponse) Decode(ptr any) error {
+	ct := ContentTypeJson
+	if tr.Resp != nil {
+		if v := tr.Resp.Header.Get(contentType); v != "" {
+			ct = v
+		}
+	}
+	return tr.decodeWith(ct, ptr)
+}
+
+// Read response as msgpack object.
+//
+// Response is always closed automatically.
+//
+// If response body is somehow empty, *miso.NoneErr is returned.
+//
+// If ptr impl [TResponseJsonCheckErr], [TResponseJsonCheckErr.CheckErr] is called after unmarshalling.
+func (tr *TResponse) Msgpack(ptr any) error {
+	return tr.decodeWith(ContentTypeMsgpack, ptr)
+}
+
+// Read response as CBOR object.
+//
+// Response is always closed automatically.
+//
+// If response body is somehow empty, *miso.NoneErr is returned.
+//
+// If ptr impl [TResponseJsonCheckErr], [TResponseJsonCheckErr.CheckErr] is called after unmarshalling.
+func (tr *TResponse) Cbor(ptr any) error {
+	return tr.decodeWith(ContentTypeCbor, ptr)
+}
+
+func (tr *TResponse) decodeWith(ct string, ptr any) error {
+	cd, ok := LookupCodec(ct)
+	if !ok || cd.ContentType() == ContentTypeJson || isProblemResp(tr.Resp) {
+		return tr.Json(ptr)
+	}
+
+	defer tr.Close()
+	if tr.Err != nil {
+		return tr.Err
+	}
+	if tr.Resp.Body == nil {
+		return NoneErr
+	}
+
+	if e := cd.Decode(tr.Resp.Body, ptr); e != nil {
+		return errs.Wrapf(e, "failed to unmarshal %v from response", cd.ContentType())
+	}
+
+	if v, ok := ptr.(TResponseJsonCheckErr); ok && v != nil {
+		if err := v.CheckErr(); err != nil {
+			return WrapErr(err)
+		}
+	}
+	return nil
+}
+
 func (tr *TResponse) Sse(parse func(e sse.Event) (stop bool, err error), options ...func(c *SseReadConfig)) error {
 	defer tr.Close()
 	if tr.Err != nil {
@@ -371,6 +448,8 @@ type Client struct {
 	discoverService bool
 	require2xx      bool
 	logBody         bool
+	retry           *RetryPolicy
+	hedge           *hedgePolicy
 
 	reqStart  time.Time
 	reqMethod string
@@ -533,6 +612,11 @@ func (t *Client) prepReqUrl() (string, error) {
 			return "", UnknownErrf(err, "Resolve service address failed, service: %v", t.serviceName)
 		}
 		url = resolved
+		if GetPropBool(PropClientTlsEnabled) {
+			if r, ok := strings.CutPrefix(url, httpProto); ok {
+				url = httpsProto + r
+			}
+		}
 	}
 
 	if !httpProtoRegex.MatchString(url) { // missing a protocol
@@ -582,6 +666,14 @@ func (t *Client) SetContentType(ct string) *Client {
 	return t
 }
 
+// Set Accept header, e.g., [ContentTypeMsgpack], such that the response is encoded by the Codec of the content type.
+//
+// Use [TResponse.Decode] to read the response.
+func (t *Client) Accept(ct string) *Client {
+	t.SetHeaders("Accept", ct)
+	return t
+}
+
 // Append 'http://' protocol.
 //
 // If service discovery is enabled, or the url contains http protocol already, this will be skipped.
@@ -734,6 +826,41 @@ func (t *Client) PostJson(body any) *TResponse {
 	return t.Post(bytes.NewReader(jsonBody))
 }
 
+// Send POST request with body encoded by the Codec of the content type, e.g., [ContentTypeMsgpack].
+//
+// Use [Client.Accept] to request the response in the same format.
+func (t *Client) PostEncoded(ct string, body any) *TResponse {
+	b, e := t.encodeBody(ct, body)
+	if e != nil {
+		return t.errorResponse(e)
+	}
+	return t.Post(b)
+}
+
+// Send PUT request with body encoded by the Codec of the content type, e.g., [ContentTypeMsgpack].
+//
+// Use [Client.Accept] to request the response in the same format.
+func (t *Client) PutEncoded(ct string, body any) *TResponse {
+	b, e := t.encodeBody(ct, body)
+	if e != nil {
+		return t.errorResponse(e)
+	}
+	return t.Put(b)
+}
+
+func (t *Client) encodeBody(ct string, body any) (io.Reader, error) {
+	cd, ok := LookupCodec(ct)
+	if !ok {
+		return nil, errs.NewErrf("no codec registered for content type: %v", ct)
+	}
+	var buf bytes.Buffer
+	if err := cd.Encode(&buf, body); err != nil {
+		return nil, errs.Wrapf(err, "failed to encode request body as %v", ct)
+	}
+	t.SetContentType(cd.ContentType())
+	return &buf, nil
+}
+
 func (t *Client) errorResponse(e error) *TResponse {
 	return &TResponse{Err: e, Ctx: t.Ctx, Rail: t.Rail, logBody: t.logBody, reqStart: t.reqStart, reqMethod: t.reqMethod, reqURL: t.reqURL, reqService: t.serviceName}
 }
@@ -861,7 +988,7 @@ func (t *Client) send(req *http.Request) *TResponse {
 	t.reqURL = req.URL.String()
 	t.reqStart = time.Now()
 
-	r, e := t.client.Do(req) // send HTTP requests
+	r, e := t.doRequest(req) // send HTTP requests
 
 	var statusCode int
 	var respHeaders http.Header
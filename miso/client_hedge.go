@@ -0,0 +1,207 @@
+package miso
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	ctxKeyLbExclude = "miso-LbExclude"
+)
+
+var (
+	hedgedRequestCounter = sync.OnceValue(func() *prometheus.CounterVec {
+		return NewPromCounterVec("miso_client_hedged_requests_total", []string{"service"})
+	})
+	hedgeWinCounter = sync.OnceValue(func() *prometheus.CounterVec {
+		return NewPromCounterVec("miso_client_hedge_wins_total", []string{"service"})
+	})
+)
+
+type hedgePolicy struct {
+	after    time.Duration
+	maxExtra int
+}
+
+// Hedge the request, i.e., when the request hasn't responded after the delay, send a duplicate request to a different
+// instance of the service, at most maxExtra duplicate requests are sent (one after each delay).
+//
+// The first successful response (not 5xx) is returned, the others are cancelled. Only GET requests with service
+// discovery enabled are hedged. Hedging happens within each attempt if [Client.Retry] is also used.
+//
+// The number of hedged requests and the number of times hedged requests win are exported as prometheus counters
+// 'miso_client_hedged_requests_total' and 'miso_client_hedge_wins_total'.
+//
+// E.g.,
+//
+//	miso.NewDynClient(rail, "/api/product", "product-service").
+//		Hedge(50*time.Millisecond, 1).
+//		Get()
+func (t *Client) Hedge(after time.Duration, maxExtra int) *Client {
+	if after <= 0 || maxExtra < 1 {
+		t.hedge = nil
+		return t
+	}
+	t.hedge = &hedgePolicy{after: after, maxExtra: maxExtra}
+	return t
+}
+
+// exclude servers that are already used by the hedged requests.
+func excludeHedgedServers(rail Rail, servers []Server) []Server {
+	exclude, ok := rail.CtxValue(ctxKeyLbExclude).([]string)
+	if !ok || len(exclude) < 1 {
+		return servers
+	}
+	filtered := make([]Server, 0, len(servers))
+	for _, s := range servers {
+		if !slices.Contains(exclude, s.ServerAddress()) {
+			filtered = append(filtered, s)
+		}
+	}
+	return filtered
+}
+
+type hedgeResult struct {
+	resp *http.Response
+	err  error
+	i    int // 0 for the original request
+}
+
+func (r hedgeResult) succeeded() bool {
+	return r.err == nil && (r.resp.StatusCode < 500 || r.resp.StatusCode == http.StatusNotImplemented)
+}
+
+func (r hedgeResult) close() {
+	if r.resp != nil && r.resp.Body != nil {
+		r.resp.Body.Close()
+	}
+}
+
+// body that cancels the request's context once it's closed.
+type cancelOnCloseBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnCloseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// send request, hedge it if necessary.
+func (t *Client) hedgeRoundTrip(req *http.Request) (*http.Response, error) {
+	h := t.hedge
+	if h == nil || !t.discoverService || req.Method != http.MethodGet ||
+		(req.Body != nil && req.Body != http.NoBody && req.GetBody == nil) {
+		return t.roundTrip(req)
+	}
+
+	parent := req.Context()
+	results := make(chan hedgeResult, h.maxExtra+1)
+	var cancels []context.CancelFunc
+	send := func(r *http.Request) {
+		ctx, cancel := context.WithCancel(parent)
+		i := len(cancels)
+		cancels = append(cancels, cancel)
+		r = r.WithContext(ctx)
+		go func() {
+			resp, err := t.roundTrip(r)
+			if err != nil || resp == nil {
+				cancel()
+			} else if resp.Body != nil {
+				resp.Body = &cancelOnCloseBody{ReadCloser: resp.Body, cancel: cancel}
+			}
+			results <- hedgeResult{resp: resp, err: err, i: i}
+		}()
+	}
+
+	used := []string{req.URL.Host}
+	send(req)
+	inflight, extra := 1, 0
+
+	timer := time.NewTimer(h.after)
+	defer timer.Stop()
+
+	var last hedgeResult
+	for inflight > 0 {
+		select {
+		case res := <-results:
+			inflight--
+			if res.succeeded() {
+				if res.i > 0 {
+					hedgeWinCounter().WithLabelValues(t.serviceName).Inc()
+				}
+				last.close()
+				for i, cancel := range cancels {
+					if i != res.i {
+						cancel()
+					}
+				}
+				if inflight > 0 {
+					go func(n int) { // losers
+						for ; n > 0; n-- {
+							(<-results).close()
+						}
+					}(inflight)
+				}
+				return res.resp, res.err
+			}
+			last.close()
+			last = res
+		case <-timer.C:
+			if extra >= h.maxExtra || parent.Err() != nil {
+				continue
+			}
+			hr, err := t.newHedgedRequest(req, used)
+			if err != nil {
+				t.Rail.Debugf("Failed to hedge request '%v %v', %v", req.Method, req.URL, err)
+				extra = h.maxExtra // no more instances available
+				continue
+			}
+			extra++
+			inflight++
+			used = append(used, hr.URL.Host)
+			hedgedRequestCounter().WithLabelValues(t.serviceName).Inc()
+			t.Rail.Debugf("Request '%v %v' hasn't responded in %v, hedged to %v", req.Method, req.URL, h.after, hr.URL.Host)
+			send(hr)
+			if extra < h.maxExtra {
+				timer.Reset(h.after)
+			}
+		}
+	}
+	return last.resp, last.err
+}
+
+// create duplicate request that is sent to a different instance of the service.
+func (t *Client) newHedgedRequest(req *http.Request, used []string) (*http.Request, error) {
+	c := *t
+	c.Rail = t.Rail.WithCtxVal(ctxKeyLbExclude, used)
+	u, err := c.prepReqUrl()
+	if err != nil {
+		return nil, err
+	}
+	pu, err := url.Parse(u)
+	if err != nil {
+		return nil, err
+	}
+	if slices.Contains(used, pu.Host) {
+		return nil, ErrServiceInstanceNotFound.WithInternalMsg("no other instance of service %v is available", t.serviceName)
+	}
+	hr := req.Clone(req.Context())
+	hr.URL = pu
+	hr.Host = ""
+	if req.GetBody != nil {
+		if hr.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	return hr, nil
+}
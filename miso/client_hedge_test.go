@@ -0,0 +1,77 @@
+package miso
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type staticServerList struct {
+	servers []Server
+}
+
+func (s staticServerList) PollInstance(rail Rail, name string) error   { return nil }
+func (s staticServerList) ListServers(rail Rail, name string) []Server { return s.servers }
+func (s staticServerList) IsSubscribed(rail Rail, service string) bool { return true }
+func (s staticServerList) Subscribe(rail Rail, service string) error   { return nil }
+func (s staticServerList) Unsubscribe(rail Rail, service string) error { return nil }
+
+func TestClientHedge(t *testing.T) {
+	m := discModule()
+	prev := m.getServerList
+	ChangeGetServerList(func() ServerList {
+		return staticServerList{servers: []Server{{Address: "10.0.0.1", Port: 8080}, {Address: "10.0.0.2", Port: 8080}}}
+	})
+	defer ChangeGetServerList(prev)
+
+	var calls, cancelled atomic.Int32
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		calls.Add(1)
+		if req.URL.Host == "10.0.0.1:8080" { // slow instance
+			select {
+			case <-time.After(500 * time.Millisecond):
+			case <-req.Context().Done():
+				cancelled.Add(1)
+				return nil, req.Context().Err()
+			}
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader([]byte(req.URL.Host))), Header: http.Header{}}, nil
+	})
+
+	for i := 0; i < 4; i++ {
+		start := time.Now()
+		s, err := NewDynClient(EmptyRail(), "/api", "hedge-service").
+			UseClient(&http.Client{Transport: transport}).
+			Hedge(20*time.Millisecond, 1).
+			Get().
+			Str()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s != "10.0.0.2:8080" {
+			t.Fatalf("expected response from 10.0.0.2:8080, got %v", s)
+		}
+		if time.Since(start) > 200*time.Millisecond {
+			t.Fatalf("request is not hedged, took %v", time.Since(start))
+		}
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if c := cancelled.Load(); c != calls.Load()-4 {
+		t.Fatalf("expected slow requests to be cancelled, calls: %v, cancelled: %v", calls.Load(), c)
+	}
+
+	// POST is not hedged
+	calls.Store(0)
+	NewDynClient(EmptyRail(), "/api", "hedge-service").
+		UseClient(&http.Client{Transport: transport}).
+		Hedge(20*time.Millisecond, 1).
+		PostJson(map[string]any{}).
+		Close()
+	if c := calls.Load(); c != 1 {
+		t.Fatalf("expected 1 call, got %v", c)
+	}
+}
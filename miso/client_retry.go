@@ -0,0 +1,231 @@
+package miso
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+
+	"github.com/curtisnewbie/miso/errs"
+	"github.com/curtisnewbie/miso/util/retry"
+)
+
+// Retry policy of [Client], see [Client.Retry].
+type RetryPolicy struct {
+	MaxAttempts     int                  // max number of attempts including the first one, no retry if it's less than 2
+	InitialBackoff  time.Duration        // backoff before the first retry, 100ms by default
+	MaxBackoff      time.Duration        // max backoff between attempts, 2s by default
+	Multiplier      float64              // backoff multiplier, 2 by default
+	Jitter          float64              // backoff is randomized by +/- Jitter (0 to 1) of itself, e.g., 0.2 for +/- 20%
+	RetryableStatus []int                // retryable http status codes, 502, 503 and 504 by default
+	RetryableErr    func(err error) bool // retryable errors, [IsRetryableNetErr] by default
+	NonIdempotent   bool                 // also retry non-idempotent methods, i.e., POST and PATCH without Idempotency-Key header
+}
+
+// Default RetryPolicy, 3 attempts with exponential backoff (100ms, 200ms) and 20% jitter.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:     3,
+		InitialBackoff:  100 * time.Millisecond,
+		MaxBackoff:      2 * time.Second,
+		Multiplier:      2,
+		Jitter:          0.2,
+		RetryableStatus: []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout},
+		RetryableErr:    IsRetryableNetErr,
+	}
+}
+
+// Check whether the error is a transient network error, e.g., connection refused, connection reset or timeout.
+//
+// Errors caused by the cancellation of the caller's context are not retryable.
+func IsRetryableNetErr(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF) {
+		return true
+	}
+	var ne net.Error
+	if errors.As(err, &ne) && ne.Timeout() {
+		return true
+	}
+	var oe *net.OpError
+	return errors.As(err, &oe) && oe.Op == "dial"
+}
+
+// Retry the request using the RetryPolicy.
+//
+// Only idempotent methods (GET, HEAD, OPTIONS, TRACE, PUT and DELETE) or requests with Idempotency-Key header are retried
+// unless [RetryPolicy.NonIdempotent] is true. Request body is buffered in memory such that it can be replayed.
+//
+// When service discovery is enabled, the service address is resolved again for each attempt.
+//
+// E.g.,
+//
+//	miso.NewDynClient(rail, "/api/order", "order-service").
+//		Retry(miso.DefaultRetryPolicy()).
+//		PostJson(req)
+func (t *Client) Retry(p RetryPolicy) *Client {
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = 100 * time.Millisecond
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = 2 * time.Second
+	}
+	if p.Multiplier < 1 {
+		p.Multiplier = 2
+	}
+	p.Jitter = min(max(p.Jitter, 0), 1)
+	if p.RetryableStatus == nil {
+		p.RetryableStatus = []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+	}
+	if p.RetryableErr == nil {
+		p.RetryableErr = IsRetryableNetErr
+	}
+	t.retry = &p
+	return t
+}
+
+func (p *RetryPolicy) retryable(req *http.Request) bool {
+	if p.MaxAttempts < 2 {
+		return false
+	}
+	if p.NonIdempotent || req.Header.Get(HeaderIdempotencyKey) != "" {
+		return true
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func (p *RetryPolicy) retryableStatus(status int) bool {
+	for _, s := range p.RetryableStatus {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff before the i-th retry (1-based).
+func (p *RetryPolicy) backoff(i int) time.Duration {
+	d := min(float64(p.InitialBackoff)*math.Pow(p.Multiplier, float64(i-1)), float64(p.MaxBackoff))
+	if p.Jitter > 0 {
+		d += d * p.Jitter * (rand.Float64()*2 - 1)
+	}
+	return time.Duration(d)
+}
+
+type retryableStatusErr struct {
+	status int
+}
+
+func (e retryableStatusErr) Error() string {
+	return "retryable status: " + http.StatusText(e.status)
+}
+
+// send request, retry it using the RetryPolicy if necessary.
+func (t *Client) doRequest(req *http.Request) (*http.Response, error) {
+	p := t.retry
+	if p == nil || !p.retryable(req) {
+		return t.hedgeRoundTrip(req)
+	}
+	if err := bufferRequestBody(req); err != nil {
+		return nil, err
+	}
+
+	attempt := 0
+	var prev *http.Response
+	r, err := retry.GetOneDyn(func() (*http.Response, error) {
+		attempt++
+		if attempt > 1 {
+			if err := t.resetRetryRequest(req); err != nil {
+				return nil, errs.Wrap(err) // not retryable
+			}
+		}
+		r, err := t.hedgeRoundTrip(req)
+		prev = r
+		if err == nil && p.retryableStatus(r.StatusCode) {
+			return r, retryableStatusErr{status: r.StatusCode}
+		}
+		return r, err
+	}, func(i int, err error) (time.Duration, bool) {
+		var se retryableStatusErr
+		isStatusErr := errors.As(err, &se)
+		if i >= p.MaxAttempts || (!isStatusErr && !p.RetryableErr(err)) {
+			return 0, false
+		}
+		wait := p.backoff(i)
+		if ctx := req.Context(); ctx.Err() != nil {
+			return 0, false
+		} else if dl, ok := ctx.Deadline(); ok && time.Until(dl) <= wait {
+			return 0, false
+		}
+		if isStatusErr {
+			t.Rail.Warnf("Request '%v %v' attempt %d/%d returned status %d, retry in %v", req.Method, req.URL, i, p.MaxAttempts, se.status, wait)
+		} else {
+			t.Rail.Warnf("Request '%v %v' attempt %d/%d failed, retry in %v, %v", req.Method, req.URL, i, p.MaxAttempts, wait, err)
+		}
+		if prev != nil && prev.Body != nil {
+			io.Copy(io.Discard, io.LimitReader(prev.Body, 4096)) // so that the connection can be reused
+			prev.Body.Close()
+		}
+		return wait, true
+	})
+
+	var se retryableStatusErr
+	if errors.As(err, &se) {
+		return r, nil // the last response is returned as is
+	}
+	return r, err
+}
+
+// prepare request for the next attempt, i.e., replay body and resolve service address again.
+func (t *Client) resetRetryRequest(req *http.Request) error {
+	if req.GetBody != nil {
+		b, err := req.GetBody()
+		if err != nil {
+			return err
+		}
+		req.Body = b
+	}
+	if t.discoverService {
+		u, err := t.prepReqUrl()
+		if err != nil {
+			return err
+		}
+		pu, err := url.Parse(u)
+		if err != nil {
+			return err
+		}
+		req.URL = pu
+		req.Host = ""
+	}
+	return nil
+}
+
+// make sure the request body can be replayed.
+func bufferRequestBody(req *http.Request) error {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody != nil {
+		return nil
+	}
+	buf, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return errs.Wrapf(err, "failed to buffer request body")
+	}
+	req.ContentLength = int64(len(buf))
+	req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(buf)), nil }
+	req.Body, _ = req.GetBody()
+	return nil
+}
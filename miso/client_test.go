@@ -2,9 +2,14 @@ package miso
 
 import (
 	"bytes"
+	"context"
 	"io"
+	"net"
 	"net/http"
+	"net/url"
+	"syscall"
 	"testing"
+	"time"
 )
 
 type roundTripFunc func(req *http.Request) (*http.Response, error)
@@ -55,3 +60,60 @@ func TestClientLbUrlMalformed(t *testing.T) {
 		t.Fatal("expected error for empty service name")
 	}
 }
+
+func TestClientRetry(t *testing.T) {
+	var bodies []string
+	status := []int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK}
+	newClient := func() *Client {
+		bodies = nil
+		return NewClient(EmptyRail(), "http://localhost/api").UseClient(&http.Client{
+			Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				var b []byte
+				if req.Body != nil {
+					b, _ = io.ReadAll(req.Body)
+				}
+				bodies = append(bodies, string(b))
+				return &http.Response{StatusCode: status[len(bodies)-1], Body: io.NopCloser(bytes.NewReader(nil)), Header: http.Header{}}, nil
+			}),
+		})
+	}
+	p := RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}
+
+	resp := newClient().Retry(p).Put(io.MultiReader(bytes.NewReader([]byte("abc"))))
+	if resp.Err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected response: %v, %v", resp.StatusCode, resp.Err)
+	}
+	resp.Close()
+	if len(bodies) != 3 || bodies[0] != "abc" || bodies[2] != "abc" {
+		t.Fatalf("request body is not replayed: %q", bodies)
+	}
+
+	// non-idempotent methods are not retried by default
+	resp = newClient().Retry(p).PostBytes([]byte("abc"))
+	resp.Close()
+	if len(bodies) != 1 || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("POST should not be retried: %q, %v", bodies, resp.StatusCode)
+	}
+	resp = newClient().Retry(p).AddHeader(HeaderIdempotencyKey, "k1").PostBytes([]byte("abc"))
+	resp.Close()
+	if len(bodies) != 3 {
+		t.Fatalf("POST with Idempotency-Key should be retried: %q", bodies)
+	}
+
+	// the last response is returned when attempts are exhausted
+	p.MaxAttempts = 2
+	resp = newClient().Retry(p).Require2xx().Get()
+	resp.Close()
+	if len(bodies) != 2 || resp.StatusCode != http.StatusBadGateway || resp.Err == nil {
+		t.Fatalf("unexpected response: %q, %v, %v", bodies, resp.StatusCode, resp.Err)
+	}
+}
+
+func TestIsRetryableNetErr(t *testing.T) {
+	if !IsRetryableNetErr(&net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}) {
+		t.Fatal("connection refused should be retryable")
+	}
+	if IsRetryableNetErr(&url.Error{Op: "Get", Err: context.Canceled}) {
+		t.Fatal("context canceled should not be retryable")
+	}
+}
@@ -0,0 +1,155 @@
+package miso
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/curtisnewbie/miso/errs"
+	"github.com/curtisnewbie/miso/util/json"
+	"github.com/gin-gonic/gin"
+	"github.com/ugorji/go/codec"
+)
+
+const (
+	ContentTypeJson    = applicationJson
+	ContentTypeMsgpack = "application/msgpack"
+	ContentTypeCbor    = "application/cbor"
+)
+
+var (
+	codecs   = map[string]Codec{}
+	codecsRw sync.RWMutex
+
+	mapStrAnyType = reflect.TypeOf(map[string]any(nil))
+)
+
+func init() {
+	RegisterCodec(jsonCodec{})
+
+	mh := &codec.MsgpackHandle{WriteExt: true}
+	mh.MapType = mapStrAnyType
+	RegisterCodec(ugorjiCodec{contentType: ContentTypeMsgpack, handle: mh})
+	RegisterCodec(ugorjiCodec{contentType: "application/x-msgpack", handle: mh})
+
+	ch := &codec.CborHandle{}
+	ch.MapType = mapStrAnyType
+	RegisterCodec(ugorjiCodec{contentType: ContentTypeCbor, handle: ch})
+}
+
+// Codec that encodes and decodes request and response body of the content type.
+type Codec interface {
+	ContentType() string
+	Encode(w io.Writer, v any) error
+	Decode(r io.Reader, ptr any) error
+}
+
+// Register Codec for the content type returned by [Codec.ContentType], previously registered Codec is replaced.
+//
+// JSON, msgpack and CBOR codecs are registered by default.
+//
+// Request body is decoded by the Codec matching the 'Content-Type' header,
+// and response body is encoded by the Codec negotiated using the 'Accept' header, JSON is used by default.
+func RegisterCodec(c Codec) {
+	codecsRw.Lock()
+	defer codecsRw.Unlock()
+	codecs[strings.ToLower(c.ContentType())] = c
+}
+
+// Lookup Codec by content type, parameters in the content type (e.g., charset) are ignored.
+func LookupCodec(contentType string) (Codec, bool) {
+	if mt, _, err := mime.ParseMediaType(contentType); err == nil {
+		contentType = mt
+	}
+	codecsRw.RLock()
+	defer codecsRw.RUnlock()
+	c, ok := codecs[strings.ToLower(strings.TrimSpace(contentType))]
+	return c, ok
+}
+
+// Select Codec based on the 'Accept' header, JSON Codec is returned if none matches.
+func negotiateCodec(accept string) Codec {
+	var best Codec
+	bestQ := 0.0
+	if accept != "" {
+		for _, v := range strings.Split(accept, ",") {
+			mt, params, err := mime.ParseMediaType(strings.TrimSpace(v))
+			if err != nil {
+				continue
+			}
+			q := 1.0
+			if qv, ok := params["q"]; ok {
+				if f, err := strconv.ParseFloat(qv, 64); err == nil {
+					q = f
+				}
+			}
+			if q <= bestQ {
+				continue
+			}
+			if c, ok := LookupCodec(mt); ok {
+				best, bestQ = c, q
+			}
+		}
+	}
+	if best == nil {
+		best, _ = LookupCodec(ContentTypeJson)
+	}
+	return best
+}
+
+// Dispatch response encoded by the negotiated Codec.
+//
+// The body is encoded before the response is written, nothing is written if the encoding fails.
+func dispatchCodec(c *gin.Context, code int, body any) error {
+	cd := negotiateCodec(c.GetHeader("Accept"))
+	var buf bytes.Buffer
+	if err := cd.Encode(&buf, body); err != nil {
+		return errs.Wrapf(err, "failed to encode response using %v codec", cd.ContentType())
+	}
+	c.Header("Content-Type", cd.ContentType())
+	c.Status(code)
+	_, err := c.Writer.Write(buf.Bytes())
+	return err
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) ContentType() string { return ContentTypeJson }
+
+func (jsonCodec) Encode(w io.Writer, v any) error { return json.EncodeJson(w, v) }
+
+func (jsonCodec) Decode(r io.Reader, ptr any) error { return json.DecodeJson(r, ptr) }
+
+// Codec backed by github.com/ugorji/go/codec, struct fields are named using `codec` or `json` tag.
+type ugorjiCodec struct {
+	contentType string
+	handle      codec.Handle
+}
+
+func (u ugorjiCodec) ContentType() string { return u.contentType }
+
+func (u ugorjiCodec) Encode(w io.Writer, v any) error {
+	return codec.NewEncoder(w, u.handle).Encode(v)
+}
+
+func (u ugorjiCodec) Decode(r io.Reader, ptr any) error {
+	return codec.NewDecoder(r, u.handle).Decode(ptr)
+}
+
+// lookup non-JSON Codec for the request body.
+func requestCodec(r *http.Request) (Codec, bool) {
+	ct := r.Header.Get("Content-Type")
+	if ct == "" {
+		return nil, false
+	}
+	c, ok := LookupCodec(ct)
+	if !ok || c.ContentType() == ContentTypeJson {
+		return nil, false
+	}
+	return c, true
+}
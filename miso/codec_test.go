@@ -0,0 +1,114 @@
+package miso
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestNegotiateCodec(t *testing.T) {
+	cases := map[string]string{
+		"":                      ContentTypeJson,
+		"*/*":                   ContentTypeJson,
+		"application/msgpack":   ContentTypeMsgpack,
+		"application/x-msgpack": "application/x-msgpack",
+		"application/cbor, application/json;q=0.5":  ContentTypeCbor,
+		"application/cbor;q=0.5, application/json":  ContentTypeJson,
+		"text/html, application/msgpack;q=0.9, */*": ContentTypeMsgpack,
+	}
+	for accept, expected := range cases {
+		if v := negotiateCodec(accept).ContentType(); v != expected {
+			t.Errorf("Accept: '%v', expected '%v', got '%v'", accept, expected, v)
+		}
+	}
+}
+
+type codecTestReq struct {
+	Name string `json:"name"`
+	Tags []string
+}
+
+type codecTestRes struct {
+	Greeting string `json:"greeting"`
+	Count    int
+}
+
+func TestCodecRoundTrip(t *testing.T) {
+	prevRoutes, prevInterceptors := lazyRouteRegistars, interceptors
+	defer func() { lazyRouteRegistars, interceptors = prevRoutes, prevInterceptors }()
+	gin.SetMode(gin.TestMode)
+
+	decl := HttpPost("/greet", AutoHandler(func(inb *Inbound, req codecTestReq) (codecTestRes, error) {
+		return codecTestRes{Greeting: "hello " + req.Name, Count: len(req.Tags)}, nil
+	}))
+	engine := gin.New()
+	engine.Handle(decl.Method, decl.Url, decl.Handler)
+	server := httptest.NewServer(engine)
+	defer server.Close()
+
+	rail := EmptyRail()
+	for _, ct := range []string{ContentTypeJson, ContentTypeMsgpack, ContentTypeCbor} {
+		tr := NewClient(rail, server.URL+"/greet").
+			Accept(ct).
+			PostEncoded(ct, codecTestReq{Name: "miso", Tags: []string{"a", "b"}})
+		if v := tr.Resp.Header.Get("Content-Type"); v != ct {
+			t.Fatalf("expected content type '%v', got '%v'", ct, v)
+		}
+		var res GnResp[codecTestRes]
+		if err := tr.Decode(&res); err != nil {
+			t.Fatalf("%v, %v", ct, err)
+		}
+		if res.Data.Greeting != "hello miso" || res.Data.Count != 2 {
+			t.Fatalf("%v, unexpected response: %+v", ct, res)
+		}
+	}
+}
+
+type failingCodec struct{}
+
+func (failingCodec) ContentType() string { return "application/x-failing" }
+
+func (failingCodec) Encode(w io.Writer, v any) error { return errors.New("not encodable") }
+
+func (failingCodec) Decode(r io.Reader, ptr any) error { return errors.New("not decodable") }
+
+func TestCodecEncodeFailure(t *testing.T) {
+	prevRoutes, prevInterceptors := lazyRouteRegistars, interceptors
+	defer func() { lazyRouteRegistars, interceptors = prevRoutes, prevInterceptors }()
+	gin.SetMode(gin.TestMode)
+	RegisterCodec(failingCodec{})
+	defer func() {
+		codecsRw.Lock()
+		delete(codecs, "application/x-failing")
+		codecsRw.Unlock()
+	}()
+
+	decl := HttpGet("/bad", ResHandler(func(inb *Inbound) (any, error) {
+		return map[string]any{"ch": make(chan int)}, nil // not encodable as json
+	}))
+	engine := gin.New()
+	engine.Handle(decl.Method, decl.Url, decl.Handler)
+	server := httptest.NewServer(engine)
+	defer server.Close()
+
+	rail := EmptyRail()
+
+	// encoding error is returned through the normal result path
+	tr := NewClient(rail, server.URL+"/bad").Get()
+	var res Resp
+	if err := tr.Decode(&res); err == nil || !res.Error || !strings.Contains(err.Error(), "failed to encode response") {
+		t.Fatalf("unexpected response: %+v, %v", res, err)
+	}
+
+	// nothing is written if the error response can't be encoded either
+	tr = NewClient(rail, server.URL+"/bad").Accept("application/x-failing").Get()
+	if tr.Err != nil || tr.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("unexpected response: %v, %v", tr.StatusCode, tr.Err)
+	}
+	tr.Close()
+}
@@ -0,0 +1,310 @@
+package miso
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	EncodingGzip   = "gzip"
+	EncodingBrotli = "br"
+)
+
+var (
+	compressionEncoders = map[string]*compressionEncoder{
+		EncodingGzip: {
+			pool: sync.Pool{New: func() any { return gzip.NewWriter(io.Discard) }},
+		},
+		EncodingBrotli: {
+			pool: sync.Pool{New: func() any { return brotli.NewWriter(io.Discard) }},
+		},
+	}
+)
+
+type resettableWriter interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+type compressionEncoder struct {
+	pool sync.Pool
+}
+
+func (e *compressionEncoder) get(w io.Writer) resettableWriter {
+	rw := e.pool.Get().(resettableWriter)
+	rw.Reset(w)
+	return rw
+}
+
+func (e *compressionEncoder) put(rw resettableWriter) {
+	rw.Reset(io.Discard)
+	e.pool.Put(rw)
+}
+
+type compressionConf struct {
+	minSize      int
+	contentTypes map[string]struct{}
+	encodings    []string
+}
+
+func (c *compressionConf) compressible(contentType string) bool {
+	if contentType == "" {
+		return false
+	}
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	_, ok := c.contentTypes[mt]
+	return ok
+}
+
+// Negotiate content-encoding with Accept-Encoding header, returns empty string if none is acceptable.
+//
+// Encodings with higher q-values are preferred, if q-values are equal, the order of the configured encodings is preferred.
+// The wildcard '*' only applies to the encodings that are not explicitly listed.
+func (c *compressionConf) negotiate(acceptEncoding string) string {
+	if acceptEncoding == "" {
+		return ""
+	}
+	qvalues := map[string]float64{}
+	for _, tok := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(tok), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		qvalues[name] = q
+	}
+
+	best, bestQ := "", 0.0
+	for _, enc := range c.encodings {
+		q, ok := qvalues[enc]
+		if !ok {
+			q = qvalues["*"]
+		}
+		if q > bestQ {
+			best, bestQ = enc, q
+		}
+	}
+	return best
+}
+
+func loadCompressionConf() *compressionConf {
+	c := &compressionConf{
+		minSize:      GetPropInt(PropServerCompressionMinSize),
+		contentTypes: map[string]struct{}{},
+	}
+	for _, ct := range GetPropStrSlice(PropServerCompressionContentTypes) {
+		c.contentTypes[strings.ToLower(strings.TrimSpace(ct))] = struct{}{}
+	}
+	for _, enc := range GetPropStrSlice(PropServerCompressionEncodings) {
+		enc = strings.ToLower(strings.TrimSpace(enc))
+		if _, ok := compressionEncoders[enc]; ok {
+			c.encodings = append(c.encodings, enc)
+		} else {
+			Warnf("Unsupported compression encoding: '%v', ignored", enc)
+		}
+	}
+	return c
+}
+
+// Compression Middleware.
+//
+// Response is compressed using gzip or brotli negotiated from the Accept-Encoding header.
+// Responses that are smaller than 'server.compression.min-size', of content types that are not included in
+// 'server.compression.content-types', already encoded (with Content-Encoding header) or SSE streams are not compressed.
+func CompressionMiddleware() gin.HandlerFunc {
+	conf := loadCompressionConf()
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodHead || c.GetHeader("Upgrade") != "" {
+			c.Next()
+			return
+		}
+		enc := conf.negotiate(c.GetHeader("Accept-Encoding"))
+		if enc == "" {
+			c.Next()
+			return
+		}
+
+		cw := &compressWriter{ResponseWriter: c.Writer, conf: conf, encName: enc, status: http.StatusOK}
+		c.Writer = cw
+		if v, ok := c.Get(ctxKeyAccessLogWriter); ok {
+			c.Writer = v.(*accessLogResponseWriter).above(cw) // access log records the uncompressed body
+		}
+		defer func() {
+			c.Writer = cw.ResponseWriter
+			if err := cw.finish(); err != nil {
+				Errorf("Failed to write compressed response, %v", err)
+			}
+		}()
+		c.Next()
+	}
+}
+
+// gin.ResponseWriter that compresses the response body.
+//
+// Body is buffered until min-size is reached, only then the decision is made whether to compress it.
+type compressWriter struct {
+	gin.ResponseWriter
+	conf    *compressionConf
+	encName string
+
+	status    int
+	decided   bool
+	compress  bool
+	buf       bytes.Buffer
+	encWriter resettableWriter
+	size      int
+}
+
+func (w *compressWriter) WriteHeader(code int) {
+	if code > 0 && !w.decided {
+		w.status = code
+	}
+}
+
+func (w *compressWriter) WriteHeaderNow() {
+	if !w.decided {
+		w.decide()
+	}
+}
+
+func (w *compressWriter) Status() int {
+	if w.decided {
+		return w.ResponseWriter.Status()
+	}
+	return w.status
+}
+
+func (w *compressWriter) Size() int {
+	if !w.decided && w.size == 0 {
+		return -1
+	}
+	return w.size
+}
+
+func (w *compressWriter) Written() bool {
+	return w.decided || w.size > 0
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *compressWriter) Write(b []byte) (int, error) {
+	w.size += len(b)
+	if !w.decided {
+		w.buf.Write(b)
+		if w.buf.Len() < w.conf.minSize && !w.skipped() {
+			return len(b), nil
+		}
+		if err := w.decide(); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+	if w.compress {
+		return w.encWriter.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		if err := w.decide(); err != nil {
+			Errorf("Failed to write compressed response, %v", err)
+			return
+		}
+	}
+	if w.compress {
+		if err := w.encWriter.Flush(); err != nil {
+			Errorf("Failed to flush compressed response, %v", err)
+			return
+		}
+	}
+	w.ResponseWriter.Flush()
+}
+
+// whether the response should not be compressed regardless of its size.
+func (w *compressWriter) skipped() bool {
+	h := w.Header()
+	if h.Get("Content-Encoding") != "" {
+		return true
+	}
+	ct := h.Get("Content-Type")
+	if strings.HasPrefix(ct, "text/event-stream") {
+		return true
+	}
+	if w.status < http.StatusOK || w.status == http.StatusNoContent || w.status == http.StatusNotModified {
+		return true
+	}
+	return !w.conf.compressible(ct)
+}
+
+// decide whether the response should be compressed, the buffered body is then written.
+func (w *compressWriter) decide() error {
+	w.decided = true
+	h := w.Header()
+	w.compress = !w.skipped() && w.buf.Len() >= w.conf.minSize
+	if w.conf.compressible(h.Get("Content-Type")) {
+		h.Add("Vary", "Accept-Encoding")
+	}
+	if w.compress {
+		h.Set("Content-Encoding", w.encName)
+		h.Del("Content-Length")
+		w.encWriter = compressionEncoders[w.encName].get(w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+
+	if w.buf.Len() < 1 {
+		w.ResponseWriter.WriteHeaderNow()
+		return nil
+	}
+	var err error
+	if w.compress {
+		_, err = w.encWriter.Write(w.buf.Bytes())
+	} else {
+		_, err = w.ResponseWriter.Write(w.buf.Bytes())
+	}
+	w.buf.Reset()
+	return err
+}
+
+func (w *compressWriter) finish() error {
+	if !w.decided {
+		if w.size < 1 && !w.ResponseWriter.Written() {
+			// nothing is written, e.g., the request is aborted with status only
+			if w.status != http.StatusOK {
+				w.ResponseWriter.WriteHeader(w.status)
+			}
+			return nil
+		}
+		if err := w.decide(); err != nil {
+			return err
+		}
+	}
+	if w.compress {
+		err := w.encWriter.Close()
+		compressionEncoders[w.encName].put(w.encWriter)
+		w.encWriter = nil
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package miso
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCompressionNegotiate(t *testing.T) {
+	conf := &compressionConf{encodings: []string{EncodingBrotli, EncodingGzip}}
+	cases := map[string]string{
+		"":                     "",
+		"identity":             "",
+		"gzip":                 EncodingGzip,
+		"gzip, br":             EncodingBrotli,
+		"gzip;q=1.0, br;q=0.5": EncodingGzip,
+		"br;q=0, gzip":         EncodingGzip,
+		"*":                    EncodingBrotli,
+		"deflate, GZIP;q=0.8":  EncodingGzip,
+		"gzip;q=0, br;q=0, *":  "",
+		"br;q=0, *;q=0.5":      EncodingGzip,
+	}
+	for ae, expected := range cases {
+		if v := conf.negotiate(ae); v != expected {
+			t.Errorf("Accept-Encoding: '%v', expected '%v', got '%v'", ae, expected, v)
+		}
+	}
+}
+
+func TestCompressionMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	SetProp(PropServerCompressionMinSize, 64)
+	SetProp(PropServerCompressionEncodings, []string{EncodingGzip})
+
+	large := strings.Repeat("miso", 100)
+	engine := gin.New()
+	engine.Use(CompressionMiddleware())
+	engine.GET("/large", func(c *gin.Context) { c.String(http.StatusOK, large) })
+	engine.GET("/small", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+	engine.GET("/binary", func(c *gin.Context) { c.Data(http.StatusOK, "application/octet-stream", []byte(large)) })
+	engine.GET("/sse", func(c *gin.Context) {
+		c.Header("Content-Type", "text/event-stream")
+		c.Status(http.StatusOK)
+		c.Writer.WriteString("data: " + large + "\n\n")
+		c.Writer.Flush()
+	})
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		r.Header.Set("Accept-Encoding", "gzip, br")
+		engine.ServeHTTP(w, r)
+		return w
+	}
+
+	w := serve("/large")
+	if w.Code != http.StatusOK || w.Header().Get("Content-Encoding") != EncodingGzip {
+		t.Fatalf("expected gzip response, got %v, %v", w.Code, w.Header())
+	}
+	if w.Header().Get("Vary") != "Accept-Encoding" {
+		t.Fatalf("missing Vary header, %v", w.Header())
+	}
+	gr, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := io.ReadAll(gr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != large {
+		t.Fatalf("unexpected body: %v", string(b))
+	}
+
+	for _, path := range []string{"/small", "/binary", "/sse"} {
+		w := serve(path)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%v, expected 200, got %v", path, w.Code)
+		}
+		if ce := w.Header().Get("Content-Encoding"); ce != "" {
+			t.Fatalf("%v, should not be compressed, got Content-Encoding: %v", path, ce)
+		}
+		if !strings.Contains(w.Body.String(), "ok") && !strings.Contains(w.Body.String(), "miso") {
+			t.Fatalf("%v, unexpected body: %v", path, w.Body.String())
+		}
+	}
+}
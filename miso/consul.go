@@ -291,7 +291,14 @@ func RegisterConsulService() error {
 	}
 	meta[ServiceMetaRegisterTime] = cast.ToString(atom.Now().UnixMilli())
 
-	completeHealthCheckUrl := fmt.Sprintf("http://%s:%v%s", registerAddress, serverPort, healthCheckUrl)
+	// health check endpoint may be served on the admin http server
+	healthCheckProto, healthCheckPort := "http", serverPort
+	if IsAdminServerEnabled() {
+		healthCheckPort = GetPropInt(PropServerAdminActualPort)
+	} else if GetPropBool(PropServerTlsEnabled) {
+		healthCheckProto = "https"
+	}
+	completeHealthCheckUrl := fmt.Sprintf("%s://%s:%v%s", healthCheckProto, registerAddress, healthCheckPort, healthCheckUrl)
 	proposedServiceId := fmt.Sprintf("%s-%d", registerName, serverPort)
 	registration := &api.AgentServiceRegistration{
 		ID:      proposedServiceId,
@@ -300,6 +307,7 @@ func RegisterConsulService() error {
 		Address: registerAddress,
 		Check: &api.AgentServiceCheck{
 			HTTP:                           completeHealthCheckUrl,
+			TLSSkipVerify:                  healthCheckProto == "https",
 			Interval:                       healthCheckInterval,
 			Timeout:                        healthCheckTimeout,
 			DeregisterCriticalServiceAfter: healthCheckDeregAfter,
@@ -421,7 +429,10 @@ func consulBootstrap(rail Rail) error {
 	AddOrderedShutdownHook(DefShutdownOrder-1, func() {
 		rail := EmptyRail()
 
-		if IsConsulServiceRegistered() {
+		// the new process has registered itself using the same service id
+		if IsGracefulRestarting() {
+			rail.Infof("Graceful restart in progress, skipped Consul deregistration")
+		} else if IsConsulServiceRegistered() {
 			if e := DeregisterConsulService(); e != nil {
 				rail.Errorf("Failed to deregister on Consul, %v", e)
 			}
@@ -0,0 +1,251 @@
+package miso
+
+import (
+	"errors"
+	"net/http"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/curtisnewbie/miso/errs"
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	corsGroups   []corsGroup
+	corsGroupsMu sync.Mutex
+)
+
+type corsGroup struct {
+	rg     *RoutingGroup
+	policy CorsPolicy
+}
+
+// CORS policy.
+type CorsPolicy struct {
+	AllowedOrigins   []string      // origin patterns, e.g., 'https://app.example.com', 'https://*.example.com'; '*' allows all origins
+	AllowedMethods   []string      // allowed methods
+	AllowedHeaders   []string      // allowed request headers; '*' allows all headers requested
+	ExposedHeaders   []string      // response headers exposed to the browser
+	AllowCredentials bool          // allow credentials, the matched request origin is echoed instead of '*', can't be used with origin '*'
+	MaxAge           time.Duration // how long the preflight results can be cached
+}
+
+// Build CorsPolicy using 'server.cors.*' props.
+func CorsPolicyFromProps() CorsPolicy {
+	return CorsPolicy{
+		AllowedOrigins:   GetPropStrSlice(PropServerCorsAllowedOrigins),
+		AllowedMethods:   GetPropStrSlice(PropServerCorsAllowedMethods),
+		AllowedHeaders:   GetPropStrSlice(PropServerCorsAllowedHeaders),
+		ExposedHeaders:   GetPropStrSlice(PropServerCorsExposedHeaders),
+		AllowCredentials: GetPropBool(PropServerCorsAllowCredentials),
+		MaxAge:           GetPropDuration(PropServerCorsMaxAge),
+	}
+}
+
+// Override CORS policy for all routes in the group (including the nested groups), regardless of 'server.cors.enabled'.
+//
+// When groups are nested, the policy of the innermost group wins.
+//
+// Must be called before the server bootstraps.
+func (rg *RoutingGroup) Cors(p CorsPolicy) *RoutingGroup {
+	corsGroupsMu.Lock()
+	defer corsGroupsMu.Unlock()
+	corsGroups = append(corsGroups, corsGroup{rg: rg, policy: p})
+	return rg
+}
+
+// CORS middleware, it's registered automatically when 'server.cors.enabled' is true or [RoutingGroup.Cors] is used.
+//
+// Preflight requests are answered by the middleware with 204 (or 403 if rejected),
+// so routes don't need to declare OPTIONS endpoints.
+//
+// Error is returned if any of the policies is invalid, e.g., allowing all origins with credentials.
+func CorsMiddleware() (gin.HandlerFunc, error) {
+	var def *corsPolicy
+	if GetPropBool(PropServerCorsEnabled) {
+		p, err := newCorsPolicy(CorsPolicyFromProps())
+		if err != nil {
+			return nil, errs.Wrapf(err, "invalid CORS configuration 'server.cors.*'")
+		}
+		def = p
+	}
+
+	type prefixPolicy struct {
+		prefix string
+		policy *corsPolicy
+	}
+	corsGroupsMu.Lock()
+	groups := make([]prefixPolicy, 0, len(corsGroups))
+	for _, g := range corsGroups {
+		prefix := strings.TrimSuffix(g.rg.fullBase(), "/")
+		p, err := newCorsPolicy(g.policy)
+		if err != nil {
+			corsGroupsMu.Unlock()
+			return nil, errs.Wrapf(err, "invalid CORS policy of routing group '%v'", prefix)
+		}
+		groups = append(groups, prefixPolicy{prefix: prefix, policy: p})
+	}
+	corsGroupsMu.Unlock()
+
+	// longest prefix first
+	slices.SortStableFunc(groups, func(a, b prefixPolicy) int { return len(b.prefix) - len(a.prefix) })
+
+	lookup := func(p string) *corsPolicy {
+		for _, g := range groups {
+			if p == g.prefix || strings.HasPrefix(p, g.prefix+"/") || g.prefix == "" {
+				return g.policy
+			}
+		}
+		return def
+	}
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+		p := lookup(c.Request.URL.Path)
+		if p == nil {
+			c.Next()
+			return
+		}
+		p.handle(c, origin)
+	}, nil
+}
+
+type corsPolicy struct {
+	CorsPolicy
+	allowAllOrigins bool
+	allowAllHeaders bool
+	origins         []string
+	methods         string
+	headers         string
+	exposed         string
+	maxAge          string
+}
+
+// build corsPolicy, returns error if the policy allows all origins with credentials.
+func newCorsPolicy(p CorsPolicy) (*corsPolicy, error) {
+	cp := &corsPolicy{CorsPolicy: p}
+	for _, o := range p.AllowedOrigins {
+		o = strings.ToLower(strings.TrimSpace(o))
+		if o == "*" {
+			cp.allowAllOrigins = true
+		}
+		cp.origins = append(cp.origins, o)
+	}
+	if cp.allowAllOrigins && p.AllowCredentials {
+		// otherwise any site can send credentialed requests
+		return nil, errors.New("CORS policy allowing all origins ('*') can't be used with credentials, specify the allowed origins explicitly")
+	}
+	methods := make([]string, 0, len(p.AllowedMethods))
+	for _, m := range p.AllowedMethods {
+		methods = append(methods, strings.ToUpper(strings.TrimSpace(m)))
+	}
+	cp.AllowedMethods = methods
+	cp.methods = strings.Join(methods, ", ")
+	for _, h := range p.AllowedHeaders {
+		if strings.TrimSpace(h) == "*" {
+			cp.allowAllHeaders = true
+		}
+	}
+	cp.headers = strings.Join(p.AllowedHeaders, ", ")
+	cp.exposed = strings.Join(p.ExposedHeaders, ", ")
+	if p.MaxAge > 0 {
+		cp.maxAge = strconv.Itoa(int(p.MaxAge.Seconds()))
+	}
+	return cp, nil
+}
+
+func (p *corsPolicy) originAllowed(origin string) bool {
+	if p.allowAllOrigins {
+		return true
+	}
+	origin = strings.ToLower(origin)
+	for _, o := range p.origins {
+		if o == origin {
+			return true
+		}
+		if strings.Contains(o, "*") {
+			if ok, _ := path.Match(o, origin); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (p *corsPolicy) handle(c *gin.Context, origin string) {
+	h := c.Writer.Header()
+	h.Add("Vary", "Origin")
+
+	preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+	if !p.originAllowed(origin) {
+		if preflight {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+		c.Next()
+		return
+	}
+
+	if p.allowAllOrigins {
+		h.Set("Access-Control-Allow-Origin", "*")
+	} else {
+		h.Set("Access-Control-Allow-Origin", origin) // matched by the allowed origins
+	}
+	if p.AllowCredentials {
+		h.Set("Access-Control-Allow-Credentials", "true")
+	}
+
+	if !preflight {
+		if p.exposed != "" {
+			h.Set("Access-Control-Expose-Headers", p.exposed)
+		}
+		c.Next()
+		return
+	}
+
+	h.Add("Vary", "Access-Control-Request-Method")
+	h.Add("Vary", "Access-Control-Request-Headers")
+	method := strings.ToUpper(c.GetHeader("Access-Control-Request-Method"))
+	if !slices.Contains(p.AllowedMethods, method) {
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+	h.Set("Access-Control-Allow-Methods", p.methods)
+	if reqHeaders := c.GetHeader("Access-Control-Request-Headers"); reqHeaders != "" {
+		if p.allowAllHeaders {
+			h.Set("Access-Control-Allow-Headers", reqHeaders)
+		} else {
+			for _, rh := range strings.Split(reqHeaders, ",") {
+				if !p.headerAllowed(strings.TrimSpace(rh)) {
+					c.AbortWithStatus(http.StatusForbidden)
+					return
+				}
+			}
+			h.Set("Access-Control-Allow-Headers", p.headers)
+		}
+	}
+	if p.maxAge != "" {
+		h.Set("Access-Control-Max-Age", p.maxAge)
+	}
+	c.AbortWithStatus(http.StatusNoContent)
+}
+
+func (p *corsPolicy) headerAllowed(header string) bool {
+	if header == "" {
+		return true
+	}
+	for _, ah := range p.AllowedHeaders {
+		if strings.EqualFold(strings.TrimSpace(ah), header) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package miso
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCorsMiddleware(t *testing.T) {
+	prevGroups := corsGroups
+	defer func() { corsGroups = prevGroups }()
+	gin.SetMode(gin.TestMode)
+	SetProp(PropServerCorsEnabled, true)
+	SetProp(PropServerCorsAllowedOrigins, []string{"https://*.example.com"})
+	SetProp(PropServerCorsExposedHeaders, []string{"X-Total"})
+	defer func() {
+		SetProp(PropServerCorsEnabled, false)
+		SetProp(PropServerCorsAllowedOrigins, []string{"*"})
+		SetProp(PropServerCorsExposedHeaders, []string{})
+	}()
+
+	pub := BaseRoute("/public")
+	GroupRoute("/open", pub) // nested group is prepended by its parent
+	pub.Cors(CorsPolicy{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET"}, AllowedHeaders: []string{"X-Token"}})
+
+	cors, err := CorsMiddleware()
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine := gin.New()
+	engine.Use(cors)
+	engine.GET("/open/api/order", func(c *gin.Context) { c.Status(http.StatusOK) })
+	engine.GET("/open/public/info", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	call := func(method string, url string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, url, nil)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		return w
+	}
+
+	w := call(http.MethodOptions, "/open/api/order", map[string]string{
+		"Origin":                         "https://app.example.com",
+		"Access-Control-Request-Method":  "POST",
+		"Access-Control-Request-Headers": "Content-Type",
+	})
+	h := w.Header()
+	if w.Code != http.StatusNoContent || h.Get("Access-Control-Allow-Origin") != "https://app.example.com" ||
+		h.Get("Access-Control-Allow-Headers") != "Content-Type" || h.Get("Access-Control-Max-Age") != "3600" {
+		t.Fatalf("unexpected preflight response: %v, %v", w.Code, h)
+	}
+
+	w = call(http.MethodOptions, "/open/api/order", map[string]string{"Origin": "https://evil.com", "Access-Control-Request-Method": "GET"})
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("preflight from disallowed origin should be rejected, %v", w.Code)
+	}
+
+	w = call(http.MethodGet, "/open/api/order", map[string]string{"Origin": "https://app.example.com"})
+	if w.Code != http.StatusOK || w.Header().Get("Access-Control-Expose-Headers") != "X-Total" {
+		t.Fatalf("unexpected response: %v, %v", w.Code, w.Header())
+	}
+
+	w = call(http.MethodGet, "/open/api/order", map[string]string{"Origin": "https://evil.com"})
+	if w.Code != http.StatusOK || w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Fatalf("disallowed origin should not receive CORS headers: %v", w.Header())
+	}
+
+	// group override
+	w = call(http.MethodOptions, "/open/public/info", map[string]string{"Origin": "https://evil.com", "Access-Control-Request-Method": "GET"})
+	if w.Code != http.StatusNoContent || w.Header().Get("Access-Control-Allow-Origin") != "*" {
+		t.Fatalf("unexpected preflight response: %v, %v", w.Code, w.Header())
+	}
+	w = call(http.MethodOptions, "/open/public/info", map[string]string{"Origin": "https://evil.com", "Access-Control-Request-Method": "POST"})
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("method not allowed by group policy, %v", w.Code)
+	}
+	w = call(http.MethodOptions, "/open/public/info", map[string]string{
+		"Origin":                         "https://evil.com",
+		"Access-Control-Request-Method":  "GET",
+		"Access-Control-Request-Headers": "X-Other",
+	})
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("header not allowed by group policy, %v", w.Code)
+	}
+}
+
+func TestCorsPolicyAllowAllOriginsWithCredentials(t *testing.T) {
+	if _, err := newCorsPolicy(CorsPolicy{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET"}, AllowCredentials: true}); err == nil {
+		t.Fatal("policy allowing all origins with credentials should be rejected")
+	}
+
+	SetProp(PropServerCorsEnabled, true)
+	SetProp(PropServerCorsAllowCredentials, true)
+	defer func() {
+		SetProp(PropServerCorsEnabled, false)
+		SetProp(PropServerCorsAllowCredentials, false)
+	}()
+	if _, err := CorsMiddleware(); err == nil {
+		t.Fatal("invalid CORS configuration should be rejected")
+	}
+}
@@ -0,0 +1,161 @@
+package miso
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/curtisnewbie/miso/errs"
+	"github.com/curtisnewbie/miso/util/json"
+)
+
+const (
+	ErrCodeInvalidCursor = "INVALID_CURSOR"
+)
+
+var (
+	ErrInvalidCursor = errs.NewErrfCode(ErrCodeInvalidCursor, "Invalid cursor").
+		WithHttpStatus(http.StatusBadRequest)
+)
+
+var cursorSecret = sync.OnceValue(func() []byte {
+	if s := GetPropStr(PropServerPaginationCursorSecret); s != "" {
+		return []byte(s)
+	}
+	Warnf("'%v' is not configured, cursors are only valid for the current process", PropServerPaginationCursorSecret)
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return b
+})
+
+// typed value in cursor token, such that the decoded value has the same type.
+//
+// Numbers are encoded as strings, such that the precision is not lost.
+type cursorValue struct {
+	T string `json:"t"`
+	V any    `json:"v"`
+}
+
+// Encode values (e.g., the sort columns of the last record in current page) as an opaque and tamper-evident cursor token.
+//
+// Supported value types are the ones supported by database/sql, i.e., string, bool, integers, floats, []byte, time.Time and driver.Valuer.
+//
+// Cursor is signed using HMAC-SHA256 with the secret configured by 'server.pagination.cursor-secret'.
+func EncodeCursor(values ...any) (string, error) {
+	cv := make([]cursorValue, 0, len(values))
+	for _, v := range values {
+		if dv, ok := v.(driver.Valuer); ok {
+			vv, err := dv.Value()
+			if err != nil {
+				return "", errs.Wrapf(err, "failed to encode cursor value: %v", v)
+			}
+			v = vv
+		}
+		switch vv := v.(type) {
+		case nil:
+			cv = append(cv, cursorValue{T: "n"})
+		case string:
+			cv = append(cv, cursorValue{T: "s", V: vv})
+		case []byte:
+			cv = append(cv, cursorValue{T: "s", V: string(vv)})
+		case bool:
+			cv = append(cv, cursorValue{T: "b", V: vv})
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+			cv = append(cv, cursorValue{T: "i", V: fmt.Sprint(vv)})
+		case float32, float64:
+			cv = append(cv, cursorValue{T: "f", V: fmt.Sprint(vv)})
+		case time.Time:
+			cv = append(cv, cursorValue{T: "t", V: vv.Format(time.RFC3339Nano)})
+		default:
+			return "", errs.NewErrf("unsupported cursor value type: %T", v)
+		}
+	}
+	payload, err := json.WriteJson(cv)
+	if err != nil {
+		return "", errs.Wrapf(err, "failed to encode cursor")
+	}
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString(payload) + "." + enc.EncodeToString(signCursor(payload)), nil
+}
+
+// Decode cursor token encoded by [EncodeCursor].
+//
+// Integers are decoded as int64, floats are decoded as float64, []byte is decoded as string.
+//
+// If the cursor is malformed or tampered, [ErrInvalidCursor] is returned.
+func DecodeCursor(cursor string) ([]any, error) {
+	p, s, ok := strings.Cut(cursor, ".")
+	if !ok {
+		return nil, ErrInvalidCursor.New()
+	}
+	enc := base64.RawURLEncoding
+	payload, err := enc.DecodeString(p)
+	if err != nil {
+		return nil, ErrInvalidCursor.Wrap(err)
+	}
+	sig, err := enc.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor.Wrap(err)
+	}
+	if !hmac.Equal(sig, signCursor(payload)) {
+		return nil, ErrInvalidCursor.WithInternalMsg("cursor signature mismatch")
+	}
+
+	var cv []cursorValue
+	if err := json.ParseJson(payload, &cv); err != nil {
+		return nil, ErrInvalidCursor.Wrap(err)
+	}
+	values := make([]any, 0, len(cv))
+	for _, v := range cv {
+		dv, err := decodeCursorValue(v)
+		if err != nil {
+			return nil, ErrInvalidCursor.Wrap(err)
+		}
+		values = append(values, dv)
+	}
+	return values, nil
+}
+
+func decodeCursorValue(v cursorValue) (any, error) {
+	switch v.T {
+	case "n":
+		return nil, nil
+	case "s":
+		if s, ok := v.V.(string); ok {
+			return s, nil
+		}
+	case "b":
+		if b, ok := v.V.(bool); ok {
+			return b, nil
+		}
+	case "i":
+		if s, ok := v.V.(string); ok {
+			return strconv.ParseInt(s, 10, 64)
+		}
+	case "f":
+		if s, ok := v.V.(string); ok {
+			return strconv.ParseFloat(s, 64)
+		}
+	case "t":
+		if s, ok := v.V.(string); ok {
+			return time.Parse(time.RFC3339Nano, s)
+		}
+	}
+	return nil, fmt.Errorf("malformed cursor value: %+v", v)
+}
+
+func signCursor(payload []byte) []byte {
+	h := hmac.New(sha256.New, cursorSecret())
+	h.Write(payload)
+	return h.Sum(nil)[:16]
+}
@@ -0,0 +1,68 @@
+package miso
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/curtisnewbie/miso/errs"
+	"github.com/curtisnewbie/miso/util/atom"
+)
+
+func TestCursor(t *testing.T) {
+	now := time.Now()
+	c, err := EncodeCursor("abc", 123, 1.5, true, now, atom.WrapTime(now), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("cursor: %v", c)
+
+	v, err := DecodeCursor(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(v) != 7 || v[0] != "abc" || v[1] != int64(123) || v[2] != 1.5 || v[3] != true || v[6] != nil {
+		t.Fatalf("unexpected values: %#v", v)
+	}
+	if tv, ok := v[4].(time.Time); !ok || !tv.Equal(now) {
+		t.Fatalf("unexpected time: %#v", v[4])
+	}
+	if tv, ok := v[5].(time.Time); !ok || !tv.Equal(now.Truncate(time.Microsecond)) { // atom.Time is stored in microseconds
+		t.Fatalf("unexpected time: %#v", v[5])
+	}
+
+	// large integers don't lose precision
+	c2, err := EncodeCursor(int64(1<<62 + 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, err := DecodeCursor(c2); err != nil || len(v) != 1 || v[0] != int64(1<<62+1) {
+		t.Fatalf("unexpected values: %#v, %v", v, err)
+	}
+
+	// tampered
+	for _, tc := range []string{"", "abc", c[:len(c)-2], "W3sidCI6ImkiLCJ2IjoxfV0" + c[len(c)-23:]} {
+		_, err := DecodeCursor(tc)
+		var me *errs.MisoErr
+		if !errors.As(err, &me) || me.Code() != ErrCodeInvalidCursor {
+			t.Fatalf("tampered cursor '%v' should be rejected, %v", tc, err)
+		}
+	}
+}
+
+func TestCursorPagingLimit(t *testing.T) {
+	if v := (CursorPaging{}).GetLimit(); v != DefaultPageLimit {
+		t.Fatalf("expected %v, got %v", DefaultPageLimit, v)
+	}
+	if v := (CursorPaging{Limit: 1_000_000}).GetLimit(); v != 1000 {
+		t.Fatalf("expected 1000, got %v", v)
+	}
+	SetProp(PropServerPaginationCursorMaxLimit, 50)
+	defer SetProp(PropServerPaginationCursorMaxLimit, 1000)
+	if v := (CursorPaging{Limit: 100}).GetLimit(); v != 50 {
+		t.Fatalf("expected 50, got %v", v)
+	}
+	if v := RespCursorPage(CursorPaging{Limit: 100}, "").Limit; v != 50 {
+		t.Fatalf("expected 50, got %v", v)
+	}
+}
@@ -16,6 +16,7 @@ import (
 
 	"github.com/curtisnewbie/miso/errs"
 	"github.com/curtisnewbie/miso/util/async"
+	"github.com/curtisnewbie/miso/util/hash"
 	"github.com/curtisnewbie/miso/util/strutil"
 )
 
@@ -66,12 +67,16 @@ type discoveryModule struct {
 
 	// ServerList based ServiceRegistry
 	//
-	// Server selection can be customized by replacing the Rule.
+	// Server selection can be customized by replacing the Rule, by default it's the LoadBalancer of the service.
 	dynamicServiceRegistry ServerListServiceRegistry
 
 	// Map of ServerChangeListeners
 	serverChangeListeners ServerChangeListenerMap
 
+	// Server list watches registered by [discoveryModule.watchServerList], keyed by 'name/service'
+	serverListWatches   hash.Set[string]
+	serverListWatchesMu sync.Mutex
+
 	// Get ServerList implementation
 	getServerList func() ServerList
 }
@@ -79,12 +84,13 @@ type discoveryModule struct {
 func newModule() *discoveryModule {
 	return &discoveryModule{
 		propBasedServiceRegistry: hardcodedServiceRegistry{},
-		dynamicServiceRegistry:   ServerListServiceRegistry{Rule: RandomServerSelector},
+		dynamicServiceRegistry:   ServerListServiceRegistry{},
 		serverChangeListeners: ServerChangeListenerMap{
 			Listeners: map[string][]func(){},
 			Pool:      async.NewAsyncPool(async.CalcPoolSize(4, 128, 512)),
 		},
-		getServerList: func() ServerList { return nil },
+		serverListWatches: hash.NewSet[string](),
+		getServerList:     func() ServerList { return nil },
 	}
 }
 
@@ -96,7 +102,7 @@ func (m *discoveryModule) changeGetServerList(f func() ServerList) {
 }
 
 func (m *discoveryModule) selectAnyServer(rail Rail, name string) (Server, error) {
-	return m.selectServer(rail, name, RandomServerSelector)
+	return m.selectServer(rail, name, LoadBalancerSelector(rail, name))
 }
 
 func (m *discoveryModule) selectServer(rail Rail, name string, selector func(servers []Server) int) (Server, error) {
@@ -122,6 +128,14 @@ func (m *discoveryModule) selectServer(rail Rail, name string, selector func(ser
 	if len(servers) < 1 {
 		return Server{}, ErrServiceInstanceNotFound.WithInternalMsg("failed to select server for %v", name)
 	}
+	servers, err := filterOpenCircuits(name, filterOutliers(name, servers))
+	if err != nil {
+		return Server{}, err
+	}
+	servers = excludeHedgedServers(rail, servers)
+	if len(servers) < 1 {
+		return Server{}, ErrServiceInstanceNotFound.WithInternalMsg("failed to select server for %v", name)
+	}
 	selected := selector(servers)
 	if selected >= 0 && selected < len(servers) {
 		return servers[selected], nil
@@ -145,6 +159,25 @@ func (m *discoveryModule) subscribeServerChanges(rail Rail, name string, cbk fun
 	return nil
 }
 
+// Watch changes of the service's server list, f is called with the latest servers once the server list is changed.
+//
+// The watch is registered at most once for each name and service.
+func (m *discoveryModule) watchServerList(name string, service string, f func(servers []Server)) {
+	m.serverListWatchesMu.Lock()
+	added := m.serverListWatches.Add(name + "/" + service)
+	m.serverListWatchesMu.Unlock()
+	if !added {
+		return
+	}
+	m.serverChangeListeners.SubscribeChange(service, func() {
+		sl := m.getServerList()
+		if sl == nil {
+			return
+		}
+		f(sl.ListServers(EmptyRail(), service))
+	})
+}
+
 func (m *discoveryModule) triggerServerChangeListeners(service string) {
 	m.serverChangeListeners.TriggerListeners(service)
 }
@@ -275,12 +308,16 @@ func (s *ServerChangeListenerMap) SubscribeChange(name string, cbk func()) {
 }
 
 type ServerListServiceRegistry struct {
-	Rule ServerSelector
+	Rule ServerSelector // optional, the LoadBalancer of the service is used by default, see [GetLoadBalancer]
 }
 
 func (c ServerListServiceRegistry) ResolveUrl(rail Rail, service string, relativeUrl string) (string, error) {
 	m := discModule()
-	server, err := m.selectServer(rail, service, c.Rule)
+	rule := c.Rule
+	if rule == nil {
+		rule = LoadBalancerSelector(rail, service)
+	}
+	server, err := m.selectServer(rail, service, rule)
 	if err != nil {
 		return "", err
 	}
@@ -298,7 +335,7 @@ func (c ServerListServiceRegistry) ListServers(rail Rail, service string) ([]Ser
 	if len(servers) < 1 {
 		return m.propBasedServiceRegistry.ListServers(rail, service)
 	}
-	return servers, nil
+	return filterOutliers(service, servers), nil
 }
 
 // Subscribe to changes to service instances.
@@ -316,7 +353,7 @@ func SelectServer(rail Rail, name string, selector func(servers []Server) int) (
 	return discModule().selectServer(rail, name, selector)
 }
 
-// Select one Server randomly.
+// Select one Server using the LoadBalancer of the service, see [GetLoadBalancer].
 func SelectAnyServer(rail Rail, name string) (Server, error) {
 	return discModule().selectAnyServer(rail, name)
 }
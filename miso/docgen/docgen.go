@@ -6,6 +6,7 @@ import (
 	"path"
 	"path/filepath"
 	"reflect"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
@@ -487,6 +488,8 @@ func BuildManualRouteDocs(files []SourceFile, modName string, l Logger, preloade
 					Desc:       ep.Desc,
 					Scope:      ep.Scope,
 					Resource:   ep.Resource,
+					RateLimit:  ep.RateLimit,
+					WebSocket:  ep.WebSocket,
 				}
 
 				for _, q := range ep.QueryParams {
@@ -501,7 +504,9 @@ func BuildManualRouteDocs(files []SourceFile, modName string, l Logger, preloade
 					doc.JsonRequestDesc = resolveTypeRef(*ep.RequestRef, pkg, misoPkg)
 				}
 
-				extractRequestParams(&doc, ep, pkg)
+				if !ep.WebSocket {
+					extractRequestParams(&doc, ep, pkg)
+				}
 
 				if ep.ResponseRef != nil {
 					ref := *ep.ResponseRef
@@ -509,7 +514,7 @@ func BuildManualRouteDocs(files []SourceFile, modName string, l Logger, preloade
 						doc.JsonResponseDesc = buildRespTypeDesc(miso.TypeDesc{TypeName: "any"}, misoPkg)
 					} else {
 						desc := resolveTypeRef(ref, pkg, misoPkg)
-						if desc.TypeName != "" && ep.Handler != "RawHandler" {
+						if desc.TypeName != "" && ep.Handler != "RawHandler" && !ep.WebSocket {
 							desc = buildRespTypeDesc(desc, misoPkg)
 						}
 						doc.JsonResponseDesc = desc
@@ -667,7 +672,20 @@ func extractRequestParams(doc *miso.HttpRouteDoc, ep *sourceparser.ParsedEndpoin
 	}
 	if queryType != "" {
 		params := lookupTagParams(queryType, pkg, miso.TagQueryParam)
-		doc.QueryParams = append(doc.QueryParams, params...)
+		files := lookupUploadFileParams(queryType, pkg)
+		if len(files) > 0 {
+			// multipart form, fields are sent in form rather than query
+			doc.MultipartFiles = append(doc.MultipartFiles, files...)
+			for _, p := range params {
+				if !slices.ContainsFunc(files, func(f miso.ParamDoc) bool { return f.Name == p.Name }) {
+					doc.FormParams = append(doc.FormParams, p)
+				}
+			}
+			doc.JsonRequestDesc = miso.TypeDesc{}
+		} else {
+			doc.QueryParams = append(doc.QueryParams, params...)
+		}
+		doc.PathParams = append(doc.PathParams, lookupTagParams(queryType, pkg, miso.TagPathParam)...)
 	}
 	headerType := ep.HeaderReqType
 	if headerType == "" && ep.RequestRef != nil {
@@ -690,6 +708,64 @@ func refTypeName(ref sourceparser.TypeRef) string {
 // lookupTagParams resolves a type by name in the package scope and extracts ParamDoc
 // entries from struct fields that have the specified struct tag.
 func lookupTagParams(typeName string, pkg *types.Package, tagKey string) []miso.ParamDoc {
+	st := lookupStructType(typeName, pkg)
+	if st == nil {
+		return nil
+	}
+
+	var params []miso.ParamDoc
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		if !field.Exported() {
+			continue
+		}
+		tag := reflect.StructTag(st.Tag(i))
+		v := tag.Get(tagKey)
+		if v == "" {
+			continue
+		}
+		params = append(params, miso.ParamDoc{Name: v, Desc: tagDesc(tag)})
+	}
+	return params
+}
+
+// lookupUploadFileParams extracts ParamDoc entries from `form` tagged fields of type miso.UploadFile,
+// *miso.UploadFile or []*miso.UploadFile.
+func lookupUploadFileParams(typeName string, pkg *types.Package) []miso.ParamDoc {
+	st := lookupStructType(typeName, pkg)
+	if st == nil {
+		return nil
+	}
+
+	var params []miso.ParamDoc
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		if !field.Exported() {
+			continue
+		}
+		tag := reflect.StructTag(st.Tag(i))
+		v := tag.Get(miso.TagQueryParam)
+		if v == "" {
+			continue
+		}
+		if !strings.HasSuffix(field.Type().String(), "/miso.UploadFile") {
+			continue
+		}
+		params = append(params, miso.ParamDoc{Name: v, Desc: tagDesc(tag)})
+	}
+	return params
+}
+
+func tagDesc(tag reflect.StructTag) string {
+	desc := tag.Get("desc")
+	if desc == "" {
+		desc = tag.Get("xdesc")
+	}
+	return desc
+}
+
+// lookupStructType resolves a struct type by name in the package scope.
+func lookupStructType(typeName string, pkg *types.Package) *types.Struct {
 	// Strip pointer/slice prefixes to get the base type name for lookup
 	lookupName := typeName
 	if strings.HasPrefix(lookupName, "*") {
@@ -712,25 +788,7 @@ func lookupTagParams(typeName string, pkg *types.Package, tagKey string) []miso.
 	if !ok {
 		return nil
 	}
-
-	var params []miso.ParamDoc
-	for i := 0; i < st.NumFields(); i++ {
-		field := st.Field(i)
-		if !field.Exported() {
-			continue
-		}
-		tag := reflect.StructTag(st.Tag(i))
-		v := tag.Get(tagKey)
-		if v == "" {
-			continue
-		}
-		desc := tag.Get("desc")
-		if desc == "" {
-			desc = tag.Get("xdesc")
-		}
-		params = append(params, miso.ParamDoc{Name: v, Desc: desc})
-	}
-	return params
+	return st
 }
 
 // The sourceparser stores AST selector expressions (e.g., "miso.ExtraNgTable"),
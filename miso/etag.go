@@ -0,0 +1,228 @@
+package miso
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/curtisnewbie/miso/errs"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	HeaderETag              = "ETag"
+	HeaderLastModified      = "Last-Modified"
+	HeaderIfNoneMatch       = "If-None-Match"
+	HeaderIfModifiedSince   = "If-Modified-Since"
+	HeaderIfMatch           = "If-Match"
+	HeaderIfUnmodifiedSince = "If-Unmodified-Since"
+
+	ErrCodePreconditionFailed = "PRECONDITION_FAILED"
+)
+
+var (
+	ErrPreconditionFailed = errs.NewErrfCode(ErrCodePreconditionFailed, "Resource has been modified").
+		WithHttpStatus(http.StatusPreconditionFailed)
+)
+
+// Current version of the resource, returned by the version func of [LazyRouteDecl.ETagFunc].
+type ResourceVersion struct {
+	ETag         string    // opaque value of the etag without quotes, e.g., revision number or hash; empty if the resource doesn't exist
+	Weak         bool      // whether the etag is weak
+	LastModified time.Time // last modified time of the resource, optional
+}
+
+// Format ETag header value.
+func (v ResourceVersion) etagHeader() string {
+	if v.ETag == "" {
+		return ""
+	}
+	if v.Weak {
+		return `W/"` + v.ETag + `"`
+	}
+	return `"` + v.ETag + `"`
+}
+
+// Compute strong ETag from the serialized response of the GET endpoint.
+//
+// If the ETag matches 'If-None-Match' header, 304 Not Modified is returned without the body.
+// The handler is still executed, use [LazyRouteDecl.ETagFunc] to avoid loading the resource.
+func (g *LazyRouteDecl) ETag() *LazyRouteDecl {
+	return g.bodyETag(false)
+}
+
+// Same as [LazyRouteDecl.ETag], but weak ETag is computed, e.g., when the response is semantically equivalent
+// but not byte-for-byte identical.
+func (g *LazyRouteDecl) WeakETag() *LazyRouteDecl {
+	return g.bodyETag(true)
+}
+
+func (g *LazyRouteDecl) bodyETag(weak bool) *LazyRouteDecl {
+	switch g.Method {
+	case http.MethodGet, http.MethodHead:
+	default:
+		panic(fmt.Errorf("ETag computed from response is only supported for GET endpoints, '%v %v'", g.Method, g.Url))
+	}
+	return g.DocHeader(HeaderIfNoneMatch, "Optional ETag of the cached response, 304 is returned if not modified.").
+		intercept(func(c *gin.Context, next func()) {
+			bw := &bufferedResponseWriter{ResponseWriter: c.Writer}
+			c.Writer = bw
+			next()
+			c.Writer = bw.ResponseWriter
+
+			body := bw.buf.Bytes()
+			if bw.Status() != http.StatusOK || bw.Header().Get(HeaderETag) != "" {
+				if _, err := c.Writer.Write(body); err != nil {
+					Errorf("Failed to write response, %v", err)
+				}
+				return
+			}
+
+			sum := sha256.Sum256(body)
+			v := ResourceVersion{ETag: hex.EncodeToString(sum[:16]), Weak: weak}
+			etag := v.etagHeader()
+			c.Header(HeaderETag, etag)
+			if etagMatches(c.GetHeader(HeaderIfNoneMatch), etag, false) {
+				writeNotModified(c)
+				return
+			}
+			if _, err := c.Writer.Write(body); err != nil {
+				Errorf("Failed to write response, %v", err)
+			}
+		})
+}
+
+// Use ETag and Last-Modified returned by the version func for conditional requests.
+//
+// The version func is called before the handler.
+//
+// For GET endpoints, if the resource matches 'If-None-Match' (or 'If-Modified-Since' when 'If-None-Match' is absent),
+// 304 Not Modified is returned without calling the handler.
+//
+// For PUT, PATCH and DELETE endpoints, if the resource doesn't match 'If-Match' (or it has been modified since 'If-Unmodified-Since'),
+// the request is rejected with 412 Precondition Failed, such that lost updates can be avoided.
+func (g *LazyRouteDecl) ETagFunc(f func(inb *Inbound) (ResourceVersion, error)) *LazyRouteDecl {
+	switch g.Method {
+	case http.MethodGet, http.MethodHead:
+		g.DocHeader(HeaderIfNoneMatch, "Optional ETag of the cached response, 304 is returned if not modified.")
+	case http.MethodPut, http.MethodPatch, http.MethodDelete:
+		g.DocHeader(HeaderIfMatch, "Optional ETag of the resource being modified, 412 is returned if the resource has been modified.")
+	default:
+		panic(fmt.Errorf("ETag is not supported for '%v %v'", g.Method, g.Url))
+	}
+	return g.intercept(func(c *gin.Context, next func()) {
+		inb := newInbound(c)
+		v, err := f(inb)
+		if err != nil {
+			inb.HandleResult(nil, err)
+			return
+		}
+		etag := v.etagHeader()
+
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead:
+			if etag != "" {
+				c.Header(HeaderETag, etag)
+			}
+			if !v.LastModified.IsZero() {
+				c.Header(HeaderLastModified, v.LastModified.UTC().Format(http.TimeFormat))
+			}
+			if notModified(c.Request, etag, v.LastModified) {
+				writeNotModified(c)
+				return
+			}
+		default:
+			if !preconditionMatches(c.Request, etag, v.LastModified) {
+				inb.Rail().Infof("Precondition failed for '%v %v', current etag: %v", c.Request.Method, c.Request.RequestURI, etag)
+				inb.HandleResult(nil, ErrPreconditionFailed.New())
+				return
+			}
+		}
+		next()
+	})
+}
+
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if inm := r.Header.Get(HeaderIfNoneMatch); inm != "" {
+		return etag != "" && etagMatches(inm, etag, false)
+	}
+	if ims := r.Header.Get(HeaderIfModifiedSince); ims != "" && !lastModified.IsZero() {
+		t, err := http.ParseTime(ims)
+		return err == nil && !lastModified.Truncate(time.Second).After(t)
+	}
+	return false
+}
+
+func preconditionMatches(r *http.Request, etag string, lastModified time.Time) bool {
+	if im := r.Header.Get(HeaderIfMatch); im != "" {
+		return etag != "" && etagMatches(im, etag, true)
+	}
+	if ius := r.Header.Get(HeaderIfUnmodifiedSince); ius != "" && !lastModified.IsZero() {
+		t, err := http.ParseTime(ius)
+		return err == nil && !lastModified.Truncate(time.Second).After(t)
+	}
+	return true
+}
+
+// Check whether the etag matches one of the etags in the header.
+//
+// With strong comparison, weak etags never match.
+func etagMatches(header string, etag string, strong bool) bool {
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+	if strong && strings.HasPrefix(etag, "W/") {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, v := range strings.Split(header, ",") {
+		v = strings.TrimSpace(v)
+		if strong && strings.HasPrefix(v, "W/") {
+			continue
+		}
+		if strings.TrimPrefix(v, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+func writeNotModified(c *gin.Context) {
+	h := c.Writer.Header()
+	h.Del("Content-Type")
+	h.Del("Content-Length")
+	c.AbortWithStatus(http.StatusNotModified)
+}
+
+// gin.ResponseWriter that buffers the response body, the body is written by the caller.
+type bufferedResponseWriter struct {
+	gin.ResponseWriter
+	buf bytes.Buffer
+}
+
+func (w *bufferedResponseWriter) WriteHeaderNow() {}
+
+func (w *bufferedResponseWriter) Write(b []byte) (int, error) {
+	return w.buf.Write(b)
+}
+
+func (w *bufferedResponseWriter) WriteString(s string) (int, error) {
+	return w.buf.WriteString(s)
+}
+
+func (w *bufferedResponseWriter) Written() bool {
+	return w.buf.Len() > 0
+}
+
+func (w *bufferedResponseWriter) Size() int {
+	if w.buf.Len() == 0 {
+		return -1
+	}
+	return w.buf.Len()
+}
+
+func (w *bufferedResponseWriter) Flush() {}
@@ -0,0 +1,100 @@
+package miso
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestEtagMatches(t *testing.T) {
+	cases := []struct {
+		header   string
+		etag     string
+		strong   bool
+		expected bool
+	}{
+		{`"a"`, `"a"`, true, true},
+		{`"b", "a"`, `"a"`, true, true},
+		{`W/"a"`, `"a"`, true, false},
+		{`W/"a"`, `"a"`, false, true},
+		{`"a"`, `W/"a"`, false, true},
+		{`"a"`, `W/"a"`, true, false},
+		{`*`, `"a"`, true, true},
+		{`"b"`, `"a"`, false, false},
+	}
+	for _, c := range cases {
+		if v := etagMatches(c.header, c.etag, c.strong); v != c.expected {
+			t.Errorf("header: %v, etag: %v, strong: %v, expected %v, got %v", c.header, c.etag, c.strong, c.expected, v)
+		}
+	}
+}
+
+func TestETag(t *testing.T) {
+	prevRoutes, prevInterceptors := lazyRouteRegistars, interceptors
+	defer func() { lazyRouteRegistars, interceptors = prevRoutes, prevInterceptors }()
+	gin.SetMode(gin.TestMode)
+
+	rev := 1
+	modTime := time.Now()
+	calls := 0
+	version := func(inb *Inbound) (ResourceVersion, error) {
+		return ResourceVersion{ETag: strconv.Itoa(rev), LastModified: modTime}, nil
+	}
+	list := HttpGet("/list", ResHandler(func(inb *Inbound) ([]string, error) { return []string{"a", "b"}, nil })).ETag()
+	get := HttpGet("/item", ResHandler(func(inb *Inbound) (int, error) { calls++; return rev, nil })).ETagFunc(version)
+	put := HttpPut("/item", ResHandler(func(inb *Inbound) (int, error) { rev++; return rev, nil })).ETagFunc(version)
+
+	engine := gin.New()
+	for _, d := range []*LazyRouteDecl{list, get, put} {
+		engine.Handle(d.Method, d.Url, d.Handler)
+	}
+	serve := func(method string, path string, headers map[string]string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(method, path, nil)
+		for k, v := range headers {
+			r.Header.Set(k, v)
+		}
+		engine.ServeHTTP(w, r)
+		return w
+	}
+
+	// etag computed from response
+	w := serve(http.MethodGet, "/list", nil)
+	etag := w.Header().Get(HeaderETag)
+	if w.Code != http.StatusOK || etag == "" || w.Body.Len() == 0 {
+		t.Fatalf("unexpected response, code: %v, etag: %v, body: %v", w.Code, etag, w.Body.String())
+	}
+	w = serve(http.MethodGet, "/list", map[string]string{HeaderIfNoneMatch: etag})
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Fatalf("expected 304, got %v, body: %v", w.Code, w.Body.String())
+	}
+
+	// etag from version func, handler is not called if not modified
+	w = serve(http.MethodGet, "/item", map[string]string{HeaderIfNoneMatch: `"1"`})
+	if w.Code != http.StatusNotModified || calls != 0 {
+		t.Fatalf("expected 304, got %v, calls: %v", w.Code, calls)
+	}
+	w = serve(http.MethodGet, "/item", map[string]string{HeaderIfModifiedSince: modTime.UTC().Format(http.TimeFormat)})
+	if w.Code != http.StatusNotModified {
+		t.Fatalf("expected 304, got %v", w.Code)
+	}
+	w = serve(http.MethodGet, "/item", map[string]string{HeaderIfNoneMatch: `"0"`})
+	if w.Code != http.StatusOK || calls != 1 || w.Header().Get(HeaderETag) != `"1"` {
+		t.Fatalf("expected 200, got %v, calls: %v, etag: %v", w.Code, calls, w.Header().Get(HeaderETag))
+	}
+
+	// lost update
+	if w = serve(http.MethodPut, "/item", map[string]string{HeaderIfMatch: `"1"`}); w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %v", w.Code)
+	}
+	if w = serve(http.MethodPut, "/item", map[string]string{HeaderIfMatch: `"1"`}); w.Code != http.StatusPreconditionFailed {
+		t.Fatalf("expected 412, got %v", w.Code)
+	}
+	if rev != 2 {
+		t.Fatalf("expected rev 2, got %v", rev)
+	}
+}
@@ -0,0 +1,266 @@
+package miso
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/curtisnewbie/miso/errs"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	HeaderIdempotencyKey      = "Idempotency-Key"
+	HeaderIdempotencyReplayed = "Idempotency-Replayed"
+
+	ErrCodeIdempotencyInFlight = "IDEMPOTENCY_IN_FLIGHT"
+	ErrCodeIdempotencyKeyReuse = "IDEMPOTENCY_KEY_REUSE"
+)
+
+var (
+	ErrIdempotencyInFlight = errs.NewErrfCode(ErrCodeIdempotencyInFlight, "Request with the same Idempotency-Key is being processed").
+				WithHttpStatus(http.StatusConflict)
+	ErrIdempotencyKeyReuse = errs.NewErrfCode(ErrCodeIdempotencyKeyReuse, "Idempotency-Key is already used by a different request").
+				WithHttpStatus(http.StatusUnprocessableEntity)
+)
+
+var (
+	idempotencyStore     IdempotencyStore
+	idempotencyStoreOnce = sync.OnceValue(func() IdempotencyStore {
+		if idempotencyStore != nil {
+			return idempotencyStore
+		}
+		return NewLocalIdempotencyStore(24*time.Hour, defaultIdempotencyLease(), 10_000)
+	})
+)
+
+// Recorded request and response for an Idempotency-Key.
+type IdempotencyRecord struct {
+	Fingerprint string      `json:"fingerprint"` // fingerprint of the request
+	InFlight    bool        `json:"inFlight"`    // whether the first request is still being processed
+	Status      int         `json:"status"`      // http status of the response
+	ContentType string      `json:"contentType"` // content-type of the response
+	Header      http.Header `json:"header"`      // headers of the response, e.g., Location, ETag and Set-Cookie
+	Body        []byte      `json:"body"`        // serialized response body
+}
+
+// Store of IdempotencyRecord.
+type IdempotencyStore interface {
+
+	// Record the in-flight IdempotencyRecord if the key is absent.
+	//
+	// The in-flight IdempotencyRecord should expire after a short lease (e.g., the request timeout),
+	// such that the key is not blocked forever if the request is never completed or aborted.
+	//
+	// If the key is present, the previously recorded IdempotencyRecord is returned with ok=false.
+	Begin(rail Rail, key string, rec IdempotencyRecord) (prev IdempotencyRecord, ok bool, err error)
+
+	// Replace the in-flight IdempotencyRecord with the completed one, the completed IdempotencyRecord is kept for the full ttl.
+	Complete(rail Rail, key string, rec IdempotencyRecord) error
+
+	// Remove the in-flight IdempotencyRecord, the request can be retried with the same key.
+	Abort(rail Rail, key string) error
+}
+
+// Replace the IdempotencyStore used by endpoints declared with [LazyRouteDecl.Idempotent].
+//
+// By default, an in-process IdempotencyStore backed by TTLCache is used, see [NewLocalIdempotencyStore].
+//
+// Must be called before the web server bootstraps.
+func SetIdempotencyStore(s IdempotencyStore) {
+	if s == nil {
+		panic("IdempotencyStore is nil")
+	}
+	idempotencyStore = s
+}
+
+// Make the endpoint idempotent using Idempotency-Key header, only POST, PUT and PATCH endpoints are supported.
+//
+// The response of the first request (status, headers and body) is recorded with the request fingerprint,
+// and it's replayed for the retries with the same Idempotency-Key.
+//
+// If the first request is still being processed, the duplicate requests are rejected with 409 Conflict.
+// If the Idempotency-Key is reused for a different request, the request is rejected with 422 Unprocessable Entity.
+// Requests without Idempotency-Key are processed as usual.
+//
+// Responses with status 5xx are not recorded, such that the requests can be retried.
+func (g *LazyRouteDecl) Idempotent() *LazyRouteDecl {
+	switch g.Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+	default:
+		panic(fmt.Errorf("idempotency is not supported for '%v %v'", g.Method, g.Url))
+	}
+	return g.DocHeader(HeaderIdempotencyKey, "Optional idempotency key, responses are replayed for retries with the same key.").
+		intercept(idempotencyInterceptor(g))
+}
+
+func idempotencyInterceptor(g *LazyRouteDecl) func(c *gin.Context, next func()) {
+	return func(c *gin.Context, next func()) {
+		ik := c.GetHeader(HeaderIdempotencyKey)
+		if ik == "" {
+			next()
+			return
+		}
+
+		inb := newInbound(c)
+		rail := inb.Rail()
+
+		body, err := inb.ReadRawBytes()
+		if err != nil {
+			inb.HandleResult(nil, err)
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body)) // it will be read again
+
+		// idempotency keys are isolated for each route and each user
+		key := g.Method + " " + g.Url + ":" + inb.Rail().User().UserNo + ":" + ik
+		fingerprint := idempotencyFingerprint(c.Request, body)
+
+		store := idempotencyStoreOnce()
+		prev, ok, err := store.Begin(rail, key, IdempotencyRecord{Fingerprint: fingerprint, InFlight: true})
+		if err != nil {
+			rail.Errorf("Failed to begin idempotent request, key: '%v', %v", key, err)
+			inb.HandleResult(nil, err)
+			return
+		}
+		if !ok {
+			if prev.Fingerprint != fingerprint {
+				inb.HandleResult(nil, ErrIdempotencyKeyReuse.New())
+				return
+			}
+			if prev.InFlight {
+				inb.HandleResult(nil, ErrIdempotencyInFlight.New())
+				return
+			}
+			rail.Infof("Replaying response for '%v %v', idempotency key: '%v'", c.Request.Method, c.Request.RequestURI, ik)
+			// headers set for the current request (e.g., by middlewares) are kept
+			h := c.Writer.Header()
+			for k, v := range prev.Header {
+				if _, ok := h[k]; !ok && k != "Content-Length" {
+					h[k] = v
+				}
+			}
+			if prev.ContentType != "" {
+				c.Header("Content-Type", prev.ContentType)
+			}
+			c.Header(HeaderIdempotencyReplayed, "true")
+			c.Status(prev.Status)
+			if _, err := c.Writer.Write(prev.Body); err != nil {
+				rail.Errorf("Failed to write replayed response, %v", err)
+			}
+			return
+		}
+
+		rw := &recordedResponseWriter{ResponseWriter: c.Writer}
+		c.Writer = rw
+		completed := false
+		defer func() {
+			c.Writer = rw.ResponseWriter
+			if completed {
+				return
+			}
+			if err := store.Abort(rail, key); err != nil {
+				rail.Errorf("Failed to abort idempotent request, key: '%v', %v", key, err)
+			}
+		}()
+
+		next()
+
+		if rw.Status() >= http.StatusInternalServerError {
+			return
+		}
+		rec := IdempotencyRecord{
+			Fingerprint: fingerprint,
+			Status:      rw.Status(),
+			ContentType: rw.Header().Get("Content-Type"),
+			Header:      rw.Header().Clone(),
+			Body:        rw.buf.Bytes(),
+		}
+		if err := store.Complete(rail, key, rec); err != nil {
+			rail.Errorf("Failed to complete idempotent request, key: '%v', %v", key, err)
+			return
+		}
+		completed = true
+	}
+}
+
+func idempotencyFingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method))
+	h.Write([]byte(r.URL.RequestURI()))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// gin.ResponseWriter that keeps a copy of the written body.
+type recordedResponseWriter struct {
+	gin.ResponseWriter
+	buf bytes.Buffer
+}
+
+func (w *recordedResponseWriter) Write(b []byte) (int, error) {
+	w.buf.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recordedResponseWriter) WriteString(s string) (int, error) {
+	w.buf.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+type localIdempotencyStore struct {
+	mu       sync.Mutex
+	inflight TTLCache[IdempotencyRecord]
+	cache    TTLCache[IdempotencyRecord]
+}
+
+func (s *localIdempotencyStore) Begin(rail Rail, key string, rec IdempotencyRecord) (IdempotencyRecord, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if prev, ok := s.cache.TryGet(key); ok {
+		return prev, false, nil
+	}
+	if prev, ok := s.inflight.TryGet(key); ok {
+		return prev, false, nil
+	}
+	s.inflight.Put(key, rec)
+	return rec, true, nil
+}
+
+func (s *localIdempotencyStore) Complete(rail Rail, key string, rec IdempotencyRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cache.Put(key, rec)
+	s.inflight.Del(key)
+	return nil
+}
+
+func (s *localIdempotencyStore) Abort(rail Rail, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inflight.Del(key)
+	return nil
+}
+
+// Create in-process IdempotencyStore backed by TTLCache.
+//
+// Completed records are kept for ttl, in-flight records are kept for lease, such that the retries are no longer
+// rejected if the first request is lost (e.g., the server crashed), at most maxSize records are kept.
+//
+// The lease should be longer than the time it takes to process the request, e.g., the request timeout.
+func NewLocalIdempotencyStore(ttl time.Duration, lease time.Duration, maxSize int) IdempotencyStore {
+	return &localIdempotencyStore{
+		inflight: NewTTLCache[IdempotencyRecord](lease, maxSize),
+		cache:    NewTTLCache[IdempotencyRecord](ttl, maxSize),
+	}
+}
+
+// Default lease of in-flight records, it's 'server.request.timeout' or 1 minute, whichever is longer.
+func defaultIdempotencyLease() time.Duration {
+	return max(GetPropDuration(PropServerRequestTimeout), time.Minute)
+}
@@ -0,0 +1,127 @@
+package miso
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestIdempotentRoute(t *testing.T) {
+	prev := lazyRouteRegistars
+	prevStore := idempotencyStoreOnce
+	defer func() {
+		lazyRouteRegistars = prev
+		idempotencyStoreOnce = prevStore
+	}()
+	store := NewLocalIdempotencyStore(time.Minute, time.Minute, 100)
+	idempotencyStoreOnce = func() IdempotencyStore { return store }
+
+	type createReq struct {
+		Name string `json:"name"`
+	}
+	var cnt int32
+	block := make(chan struct{})
+	decl := HttpPost("/idempotent", AutoHandler(func(inb *Inbound, req createReq) (int32, error) {
+		if req.Name == "block" {
+			<-block
+		}
+		n := atomic.AddInt32(&cnt, 1)
+		w, _ := inb.Unwrap()
+		w.Header().Set("Location", fmt.Sprintf("/idempotent/%d", n))
+		w.Header().Add("Set-Cookie", "a=1")
+		w.Header().Add("Set-Cookie", "b=2")
+		return n, nil
+	})).Idempotent()
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Handle(decl.Method, decl.Url, decl.Handler)
+
+	send := func(key string, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/idempotent", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		if key != "" {
+			r.Header.Set(HeaderIdempotencyKey, key)
+		}
+		engine.ServeHTTP(w, r)
+		return w
+	}
+
+	w1 := send("k1", `{"name":"a"}`)
+	w2 := send("k1", `{"name":"a"}`)
+	if w1.Code != http.StatusOK || w2.Code != http.StatusOK {
+		t.Fatalf("unexpected status, %v, %v", w1.Code, w2.Code)
+	}
+	if w1.Body.String() != w2.Body.String() {
+		t.Fatalf("response not replayed, %v, %v", w1.Body.String(), w2.Body.String())
+	}
+	if w2.Header().Get(HeaderIdempotencyReplayed) != "true" {
+		t.Fatal("response should be marked as replayed")
+	}
+	if w2.Header().Get("Location") != w1.Header().Get("Location") || len(w2.Header().Values("Set-Cookie")) != 2 {
+		t.Fatalf("response headers not replayed, %v", w2.Header())
+	}
+	if cnt != 1 {
+		t.Fatalf("handler should be called once, but called %v times", cnt)
+	}
+
+	if w := send("k1", `{"name":"b"}`); w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %v", w.Code)
+	}
+
+	if w := send("", `{"name":"a"}`); w.Code != http.StatusOK || cnt != 2 {
+		t.Fatalf("request without idempotency key should be processed, %v, %v", w.Code, cnt)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if w := send("k2", `{"name":"block"}`); w.Code != http.StatusOK {
+			t.Errorf("expected 200, got %v", w.Code)
+		}
+	}()
+	// wait until the first request is in-flight
+	for !store.(*localIdempotencyStore).inflight.Exists("POST /idempotent::k2") {
+		time.Sleep(time.Millisecond)
+	}
+	if w := send("k2", `{"name":"block"}`); w.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %v", w.Code)
+	}
+	close(block)
+	<-done
+}
+
+func TestLocalIdempotencyStoreLease(t *testing.T) {
+	rail := EmptyRail()
+	store := NewLocalIdempotencyStore(time.Minute, 20*time.Millisecond, 100)
+	rec := IdempotencyRecord{Fingerprint: "f", InFlight: true}
+
+	if _, ok, _ := store.Begin(rail, "k", rec); !ok {
+		t.Fatal("first request should begin")
+	}
+	if prev, ok, _ := store.Begin(rail, "k", rec); ok || !prev.InFlight {
+		t.Fatalf("duplicate request should see in-flight record, ok: %v, prev: %+v", ok, prev)
+	}
+
+	// lost in-flight record expires after the lease
+	time.Sleep(30 * time.Millisecond)
+	if _, ok, _ := store.Begin(rail, "k", rec); !ok {
+		t.Fatal("request should begin once the lease expires")
+	}
+
+	// completed record is kept for the full ttl
+	if err := store.Complete(rail, "k", IdempotencyRecord{Fingerprint: "f", Status: http.StatusOK}); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if prev, ok, _ := store.Begin(rail, "k", rec); ok || prev.InFlight || prev.Status != http.StatusOK {
+		t.Fatalf("completed record should be replayed, ok: %v, prev: %+v", ok, prev)
+	}
+}
@@ -0,0 +1,273 @@
+package miso
+
+import (
+	"fmt"
+	"hash/crc32"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/curtisnewbie/miso/util/hash"
+)
+
+const (
+	LbRandom             = "random"
+	LbRoundRobin         = "round-robin"
+	LbWeightedRoundRobin = "weighted-round-robin"
+	LbLeastRequests      = "least-requests"
+	LbConsistentHash     = "consistent-hash"
+
+	// Weight of the server in [Server.Meta], used by [LbWeightedRoundRobin] and [LbConsistentHash], 1 by default.
+	ServerMetaWeight = "weight"
+
+	ctxKeyLbKey = "miso-LbKey"
+
+	consistentHashVirtualNodes = 100
+)
+
+var (
+	lbFactoriesMu sync.RWMutex
+	lbFactories   = map[string]func(service string) LoadBalancer{
+		LbRandom:             func(service string) LoadBalancer { return LoadBalancerFunc(randomLb) },
+		LbRoundRobin:         func(service string) LoadBalancer { return &roundRobinLb{} },
+		LbWeightedRoundRobin: func(service string) LoadBalancer { return &weightedRoundRobinLb{} },
+		LbLeastRequests:      func(service string) LoadBalancer { return LoadBalancerFunc(leastRequestsLb) },
+		LbConsistentHash:     func(service string) LoadBalancer { return &consistentHashLb{} },
+	}
+
+	// LoadBalancer of each service.
+	loadBalancers = hash.NewStrRWMap[LoadBalancer]()
+
+	// number of in-flight requests of each server, keyed by 'host:port'.
+	inflightRequests = hash.NewStrRWMap[*atomic.Int64]()
+)
+
+// Load balancer that selects one of the servers of a service.
+type LoadBalancer interface {
+	// Select one of the servers, returns index of the selected one.
+	Select(rail Rail, servers []Server) int
+}
+
+// Func that implements [LoadBalancer].
+type LoadBalancerFunc func(rail Rail, servers []Server) int
+
+func (f LoadBalancerFunc) Select(rail Rail, servers []Server) int {
+	return f(rail, servers)
+}
+
+// Register LoadBalancer by name, newLb is called once for each service that uses the LoadBalancer.
+//
+// The LoadBalancer is selected using 'service-discovery.lb.${SERVICE_NAME}' or 'service-discovery.default-lb'.
+func RegisterLoadBalancer(name string, newLb func(service string) LoadBalancer) {
+	lbFactoriesMu.Lock()
+	defer lbFactoriesMu.Unlock()
+	lbFactories[name] = newLb
+}
+
+// Get LoadBalancer of the service.
+//
+// The LoadBalancer is selected using 'service-discovery.lb.${SERVICE_NAME}' or 'service-discovery.default-lb',
+// unknown LoadBalancer falls back to [LbRandom].
+func GetLoadBalancer(service string) LoadBalancer {
+	lb, _ := loadBalancers.GetElse(service, func(service string) LoadBalancer {
+		name := GetPropStr("service-discovery.lb." + service)
+		if name == "" {
+			name = GetPropStr(PropSDDefaultLb)
+		}
+		lbFactoriesMu.RLock()
+		newLb, ok := lbFactories[name]
+		lbFactoriesMu.RUnlock()
+		if !ok {
+			Warnf("Unknown load balancer '%v' for service %v, using %v instead", name, service, LbRandom)
+			return LoadBalancerFunc(randomLb)
+		}
+		return newLb(service)
+	})
+	return lb
+}
+
+// Create ServerSelector using the LoadBalancer of the service.
+//
+// E.g.,
+//
+//	server, err := miso.SelectServer(rail, "order-service", miso.LoadBalancerSelector(rail, "order-service"))
+func LoadBalancerSelector(rail Rail, service string) ServerSelector {
+	lb := GetLoadBalancer(service)
+	return func(servers []Server) int {
+		return lb.Select(rail, servers)
+	}
+}
+
+// Attach key to the rail that is used by [LbConsistentHash], requests with the same key are routed to the same server.
+//
+// Without the key, [LbConsistentHash] selects server randomly.
+func WithLbKey(rail Rail, key string) Rail {
+	return rail.WithCtxVal(ctxKeyLbKey, key)
+}
+
+// Set key for [LbConsistentHash], requests with the same key are routed to the same server, e.g., user no for cache affinity.
+func (t *Client) LbKey(key string) *Client {
+	t.Rail = WithLbKey(t.Rail, key)
+	return t
+}
+
+// Get weight of the server, see [ServerMetaWeight], weights may be fractional, e.g., Nacos weights.
+func ServerWeight(s Server) float64 {
+	if v, ok := s.Meta[ServerMetaWeight]; ok {
+		if w, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+			return max(w, 0)
+		}
+	}
+	return 1
+}
+
+// Count in-flight requests of the server, e.g., '127.0.0.1:8080'.
+//
+// Only requests sent by [Client] with service discovery enabled or proxied by [NewDynProxyTargetResolver] are counted.
+func InflightRequests(address string) int64 {
+	if v, ok := inflightRequests.Get(address); ok {
+		return v.Load()
+	}
+	return 0
+}
+
+// record in-flight request of the server.
+func lbRequestStarted(address string) (done func()) {
+	v, _ := inflightRequests.GetElse(address, func(k string) *atomic.Int64 { return &atomic.Int64{} })
+	v.Add(1)
+	return func() { v.Add(-1) }
+}
+
+func randomLb(rail Rail, servers []Server) int {
+	return RandomServerSelector(servers)
+}
+
+type roundRobinLb struct {
+	n atomic.Uint64
+}
+
+func (r *roundRobinLb) Select(rail Rail, servers []Server) int {
+	if len(servers) < 1 {
+		return -1
+	}
+	return int((r.n.Add(1) - 1) % uint64(len(servers)))
+}
+
+// smooth weighted round-robin, same as the one in nginx.
+type weightedRoundRobinLb struct {
+	mu      sync.Mutex
+	current map[string]float64
+}
+
+func (r *weightedRoundRobinLb) Select(rail Rail, servers []Server) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current := make(map[string]float64, len(servers))
+	selected, total := -1, 0.0
+	for i, s := range servers {
+		w := ServerWeight(s)
+		if w <= 0 {
+			continue
+		}
+		addr := s.ServerAddress()
+		cw := r.current[addr] + w
+		current[addr] = cw
+		total += w
+		if selected < 0 || cw > current[servers[selected].ServerAddress()] {
+			selected = i
+		}
+	}
+	if selected < 0 {
+		return RandomServerSelector(servers)
+	}
+	current[servers[selected].ServerAddress()] -= total
+	r.current = current // servers that are no longer available are removed
+	return selected
+}
+
+func leastRequestsLb(rail Rail, servers []Server) int {
+	selected, ties := -1, 0
+	var least int64
+	for i, s := range servers {
+		n := InflightRequests(s.ServerAddress())
+		switch {
+		case selected < 0 || n < least:
+			selected, least, ties = i, n, 1
+		case n == least:
+			ties++
+			if rand.IntN(ties) == 0 {
+				selected = i
+			}
+		}
+	}
+	return selected
+}
+
+type hashRingNode struct {
+	hash    uint32
+	address string
+}
+
+type consistentHashLb struct {
+	mu      sync.Mutex
+	servers string // addresses and weights of servers that the ring is built for
+	ring    []hashRingNode
+}
+
+func (c *consistentHashLb) Select(rail Rail, servers []Server) int {
+	key := rail.CtxValStr(ctxKeyLbKey)
+	if key == "" || len(servers) < 1 {
+		return RandomServerSelector(servers)
+	}
+
+	ring := c.getRing(servers)
+	if len(ring) < 1 {
+		return RandomServerSelector(servers)
+	}
+	h := crc32.ChecksumIEEE([]byte(key))
+	i := sort.Search(len(ring), func(i int) bool { return ring[i].hash >= h })
+	if i == len(ring) {
+		i = 0
+	}
+	for j, s := range servers {
+		if s.ServerAddress() == ring[i].address {
+			return j
+		}
+	}
+	return RandomServerSelector(servers)
+}
+
+func (c *consistentHashLb) getRing(servers []Server) []hashRingNode {
+	keys := make([]string, 0, len(servers))
+	for _, s := range servers {
+		keys = append(keys, fmt.Sprintf("%s*%v", s.ServerAddress(), ServerWeight(s)))
+	}
+	sort.Strings(keys)
+	sig := strings.Join(keys, ",")
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.servers == sig {
+		return c.ring
+	}
+
+	ring := make([]hashRingNode, 0, len(servers)*consistentHashVirtualNodes)
+	for _, s := range servers {
+		addr := s.ServerAddress()
+		nodes := int(math.Round(ServerWeight(s) * consistentHashVirtualNodes))
+		if nodes < 1 && ServerWeight(s) > 0 {
+			nodes = 1
+		}
+		for i := 0; i < nodes; i++ {
+			ring = append(ring, hashRingNode{hash: crc32.ChecksumIEEE([]byte(addr + "#" + strconv.Itoa(i))), address: addr})
+		}
+	}
+	sort.Slice(ring, func(i, j int) bool { return ring[i].hash < ring[j].hash })
+	c.servers, c.ring = sig, ring
+	return ring
+}
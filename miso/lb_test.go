@@ -0,0 +1,132 @@
+package miso
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestLoadBalancer(t *testing.T) {
+	servers := []Server{
+		{Address: "10.0.0.1", Port: 8080, Meta: map[string]string{ServerMetaWeight: "3"}},
+		{Address: "10.0.0.2", Port: 8080},
+	}
+	rail := EmptyRail()
+
+	rr := &roundRobinLb{}
+	for i := 0; i < 4; i++ {
+		if v := rr.Select(rail, servers); v != i%2 {
+			t.Fatalf("round-robin, expected %v, got %v", i%2, v)
+		}
+	}
+
+	wrr := &weightedRoundRobinLb{}
+	cnt := map[int]int{}
+	seq := ""
+	for i := 0; i < 8; i++ {
+		v := wrr.Select(rail, servers)
+		cnt[v]++
+		seq += fmt.Sprint(v)
+	}
+	if cnt[0] != 6 || cnt[1] != 2 {
+		t.Fatalf("weighted round-robin, unexpected distribution: %v", cnt)
+	}
+	if seq != "00100010" {
+		t.Fatalf("weighted round-robin, unexpected sequence: %v", seq)
+	}
+
+	// fractional weights, e.g., Nacos weights
+	fservers := []Server{
+		{Address: "10.0.0.1", Port: 8080, Meta: map[string]string{ServerMetaWeight: "1.5"}},
+		{Address: "10.0.0.2", Port: 8080, Meta: map[string]string{ServerMetaWeight: "1.0"}},
+		{Address: "10.0.0.3", Port: 8080, Meta: map[string]string{ServerMetaWeight: "0.5"}},
+	}
+	fwrr := &weightedRoundRobinLb{}
+	cnt = map[int]int{}
+	for i := 0; i < 30; i++ {
+		cnt[fwrr.Select(rail, fservers)]++
+	}
+	if cnt[0] != 15 || cnt[1] != 10 || cnt[2] != 5 {
+		t.Fatalf("weighted round-robin, unexpected distribution with fractional weights: %v", cnt)
+	}
+	fch := &consistentHashLb{}
+	cnt = map[int]int{}
+	for i := 0; i < 3000; i++ {
+		cnt[fch.Select(WithLbKey(rail, fmt.Sprint(i)), fservers)]++
+	}
+	if cnt[2] < 1 || cnt[2] >= cnt[1] || cnt[1] >= cnt[0] {
+		t.Fatalf("consistent-hash, unexpected distribution with fractional weights: %v", cnt)
+	}
+
+	done := lbRequestStarted(servers[0].ServerAddress())
+	if v := leastRequestsLb(rail, servers); v != 1 {
+		t.Fatalf("least-requests, expected 1, got %v", v)
+	}
+	done()
+	if n := InflightRequests(servers[0].ServerAddress()); n != 0 {
+		t.Fatalf("expected 0 in-flight requests, got %v", n)
+	}
+
+	ch := &consistentHashLb{}
+	for _, k := range []string{"user-1", "user-2", "user-3"} {
+		krail := WithLbKey(rail, k)
+		first := ch.Select(krail, servers)
+		for i := 0; i < 10; i++ {
+			if v := ch.Select(krail, servers); v != first {
+				t.Fatalf("consistent-hash, key %v expected %v, got %v", k, first, v)
+			}
+		}
+		// same server is selected regardless of the order of servers
+		if v := ch.Select(krail, []Server{servers[1], servers[0]}); v != 1-first {
+			t.Fatalf("consistent-hash, key %v expected %v, got %v", k, 1-first, v)
+		}
+	}
+}
+
+func TestGetLoadBalancer(t *testing.T) {
+	SetProp("service-discovery.lb.lb-service", LbRoundRobin)
+	SetProp("service-discovery.lb.lb-unknown-service", "unknown")
+	defer func() {
+		SetProp("service-discovery.lb.lb-service", "")
+		SetProp("service-discovery.lb.lb-unknown-service", "")
+		loadBalancers.Del("lb-service")
+		loadBalancers.Del("lb-unknown-service")
+		loadBalancers.Del("lb-default-service")
+	}()
+
+	if _, ok := GetLoadBalancer("lb-service").(*roundRobinLb); !ok {
+		t.Fatalf("expected round-robin, got %T", GetLoadBalancer("lb-service"))
+	}
+	if _, ok := GetLoadBalancer("lb-unknown-service").(LoadBalancerFunc); !ok {
+		t.Fatalf("expected random, got %T", GetLoadBalancer("lb-unknown-service"))
+	}
+	if _, ok := GetLoadBalancer("lb-default-service").(LoadBalancerFunc); !ok {
+		t.Fatalf("expected random, got %T", GetLoadBalancer("lb-default-service"))
+	}
+}
+
+func TestSelectAnyServerLoadBalancer(t *testing.T) {
+	m := discModule()
+	prev := m.getServerList
+	ChangeGetServerList(func() ServerList {
+		return staticServerList{servers: []Server{{Address: "10.0.0.1", Port: 8080}, {Address: "10.0.0.2", Port: 8080}}}
+	})
+	SetProp("service-discovery.lb.lb-any-service", LbRoundRobin)
+	defer func() {
+		ChangeGetServerList(prev)
+		SetProp("service-discovery.lb.lb-any-service", "")
+		loadBalancers.Del("lb-any-service")
+	}()
+
+	rail := EmptyRail()
+	var last string
+	for i := range 4 {
+		s, err := SelectAnyServer(rail, "lb-any-service")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.ServerAddress() == last {
+			t.Fatalf("selection %v is not round-robin, %v", i, last)
+		}
+		last = s.ServerAddress()
+	}
+}
@@ -0,0 +1,247 @@
+package miso
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+
+	"github.com/curtisnewbie/miso/errs"
+	"github.com/curtisnewbie/miso/util/osutil"
+	"github.com/curtisnewbie/miso/util/rfutil"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+const (
+	ErrCodeRequestEntityTooLarge = "REQUEST_ENTITY_TOO_LARGE"
+
+	ctxKeyUploadTmpFiles = "miso-UploadTmpFiles"
+)
+
+var (
+	ErrRequestEntityTooLarge = errs.NewErrfCode(ErrCodeRequestEntityTooLarge, "Request Entity Too Large").
+					WithHttpStatus(http.StatusRequestEntityTooLarge)
+
+	uploadFileType = reflect.TypeOf(UploadFile{})
+)
+
+// File uploaded in multipart form, bound to the request struct field using `form:"name"` tag.
+//
+// Supported field types are UploadFile, *UploadFile and []*UploadFile.
+//
+// Small files are kept in memory, files that are larger than 'server.multipart.max-memory' are saved to temp files,
+// these temp files are removed once the request is handled.
+type UploadFile struct {
+	Filename    string // name of the file provided by the client
+	ContentType string // content type of the file provided by the client
+	Size        int64  // size of the file in bytes
+
+	data    []byte
+	tmpPath string
+}
+
+func (f UploadFile) String() string {
+	return fmt.Sprintf("{Filename:%v ContentType:%v Size:%v}", f.Filename, f.ContentType, f.Size)
+}
+
+// Open the uploaded file.
+func (f *UploadFile) Open() (io.ReadCloser, error) {
+	if f.tmpPath != "" {
+		fi, err := os.Open(f.tmpPath)
+		return fi, errs.Wrap(err)
+	}
+	return io.NopCloser(bytes.NewReader(f.data)), nil
+}
+
+// Copy the uploaded file to the given path.
+func (f *UploadFile) SaveAs(path string) error {
+	r, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	w, err := osutil.OpenRWFile(path, true)
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	defer w.Close()
+	if err := w.Truncate(0); err != nil {
+		return errs.Wrap(err)
+	}
+	_, err = io.Copy(w, r)
+	return errs.Wrap(err)
+}
+
+// Limit the size of the request body in bytes, by default it's 'server.multipart.max-size'.
+//
+// Requests exceeding the limit are rejected with 413 Request Entity Too Large.
+func (g *LazyRouteDecl) MaxUploadSize(n int64) *LazyRouteDecl {
+	if n < 1 {
+		panic(fmt.Errorf("invalid max upload size: %v", n))
+	}
+	g.maxUploadSize = n
+	return g
+}
+
+// Bind multipart form fields and files to ptr, query parameters are also bound.
+//
+// The size of the request body is limited by the request limiter, see [LazyRouteDecl.MaxUploadSize].
+func bindMultipart(rail Rail, c *gin.Context, ptr any) error {
+	mr, err := c.Request.MultipartReader()
+	if err != nil {
+		return errs.Wrap(err)
+	}
+
+	values := url.Values{}
+	for k, v := range c.Request.URL.Query() {
+		values[k] = v
+	}
+	files := map[string][]*UploadFile{}
+	maxMemory := int64(GetPropInt(PropServerMultipartMaxMemory))
+
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return wrapMultipartErr(err)
+		}
+		name := p.FormName()
+		if name == "" {
+			p.Close()
+			continue
+		}
+		if p.FileName() == "" {
+			var buf bytes.Buffer
+			if _, err := io.Copy(&buf, p); err != nil {
+				return wrapMultipartErr(err)
+			}
+			values.Add(name, buf.String())
+			p.Close()
+			continue
+		}
+
+		f, err := readUploadFile(c, p, maxMemory)
+		p.Close()
+		if err != nil {
+			return wrapMultipartErr(err)
+		}
+		files[name] = append(files[name], f)
+		rail.Debugf("Received upload file '%v' (%v bytes) for field '%v'", f.Filename, f.Size, name)
+	}
+
+	if err := binding.MapFormWithTag(ptr, values, TagQueryParam); err != nil {
+		return errs.Wrap(err)
+	}
+	return rfutil.WalkTagShallow(ptr, rfutil.WalkTagCallback{
+		Tag: TagQueryParam,
+		OnWalked: func(tagVal string, fieldVal reflect.Value, fieldType reflect.StructField) error {
+			fs := files[tagVal]
+			if len(fs) < 1 {
+				return nil
+			}
+			switch {
+			case fieldType.Type == uploadFileType:
+				fieldVal.Set(reflect.ValueOf(*fs[0]))
+			case fieldType.Type == reflect.PointerTo(uploadFileType):
+				fieldVal.Set(reflect.ValueOf(fs[0]))
+			case fieldType.Type == reflect.SliceOf(reflect.PointerTo(uploadFileType)):
+				fieldVal.Set(reflect.ValueOf(fs))
+			}
+			return nil
+		},
+	})
+}
+
+// read the file part, the file is saved to temp file if it's larger than maxMemory.
+func readUploadFile(c *gin.Context, p *multipart.Part, maxMemory int64) (*UploadFile, error) {
+	f := &UploadFile{Filename: p.FileName(), ContentType: p.Header.Get("Content-Type")}
+	var buf bytes.Buffer
+	n, err := io.CopyN(&buf, p, maxMemory+1)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if n <= maxMemory {
+		f.data = buf.Bytes()
+		f.Size = n
+		return f, nil
+	}
+
+	// spill to temp file
+	tmp, err := osutil.NewTmpFile()
+	if err != nil {
+		return nil, err
+	}
+	defer tmp.Close()
+	f.tmpPath = tmp.Name()
+	addUploadTmpFile(c, f.tmpPath)
+
+	size, err := io.Copy(tmp, io.MultiReader(&buf, p))
+	if err != nil {
+		return nil, err
+	}
+	f.Size = size
+	return f, nil
+}
+
+func wrapMultipartErr(err error) error {
+	var mbe *http.MaxBytesError
+	if errors.As(err, &mbe) {
+		return ErrRequestEntityTooLarge.New()
+	}
+	return errs.Wrap(err)
+}
+
+func addUploadTmpFile(c *gin.Context, path string) {
+	var l []string
+	if v, ok := c.Get(ctxKeyUploadTmpFiles); ok {
+		l, _ = v.([]string)
+	}
+	c.Set(ctxKeyUploadTmpFiles, append(l, path))
+}
+
+// Remove temp files created for the uploaded files.
+func removeUploadTmpFiles(c *gin.Context) {
+	v, ok := c.Get(ctxKeyUploadTmpFiles)
+	if !ok {
+		return
+	}
+	l, _ := v.([]string)
+	for _, p := range l {
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			Warnf("Failed to remove upload temp file '%v', %v", p, err)
+		}
+	}
+}
+
+// Bind gin path parameters using `path:"name"` tag.
+//
+// Returns error with http status 400 if the path parameter can't be converted to the field's type.
+func bindPathParams(c *gin.Context, ptr any) error {
+	if len(c.Params) < 1 {
+		return nil
+	}
+	return rfutil.WalkTagShallow(ptr, rfutil.WalkTagCallback{
+		Tag: TagPathParam,
+		OnWalked: func(tagVal string, fieldVal reflect.Value, fieldType reflect.StructField) error {
+			v, ok := c.Params.Get(tagVal)
+			if !ok {
+				return nil
+			}
+			if err := reflectSetStrValue(fieldVal, fieldType.Type, v); err != nil {
+				return errs.NewErrf("Illegal Arguments").
+					WithHttpStatus(http.StatusBadRequest).
+					WithInternalMsg("invalid path parameter '%v': '%v', %v", tagVal, v, err)
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,111 @@
+package miso
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type multipartTestReq struct {
+	Id    int           `path:"id"`
+	Name  string        `form:"name"`
+	Tag   string        `form:"tag"`
+	File  *UploadFile   `form:"file"`
+	Files []*UploadFile `form:"files"`
+}
+
+func TestMultipartBinding(t *testing.T) {
+	prevRoutes, prevInterceptors := lazyRouteRegistars, interceptors
+	defer func() { lazyRouteRegistars, interceptors = prevRoutes, prevInterceptors }()
+
+	gin.SetMode(gin.TestMode)
+	SetProp(PropServerMultipartMaxMemory, 16)
+	defer SetProp(PropServerMultipartMaxMemory, 1048576)
+
+	var got multipartTestReq
+	var content string
+	var tmpPath string
+	handler := func(inb *Inbound, req multipartTestReq) (any, error) {
+		got = req
+		if req.File != nil {
+			r, err := req.File.Open()
+			if err != nil {
+				return nil, err
+			}
+			defer r.Close()
+			b, _ := io.ReadAll(r)
+			content = string(b)
+			tmpPath = req.File.tmpPath
+		}
+		return nil, nil
+	}
+	upload := HttpPost("/upload/:id", AutoHandler(handler))
+	limited := HttpPost("/limited/:id", AutoHandler(handler)).MaxUploadSize(128)
+
+	engine := gin.New()
+	engine.Use(gin.CustomRecovery(DefaultRecovery))
+	upload.build(engine)
+	limited.build(engine)
+
+	serve := func(path string, fileContent string) int {
+		var body bytes.Buffer
+		w := multipart.NewWriter(&body)
+		w.WriteField("name", "miso")
+		fw, _ := w.CreateFormFile("file", "a.txt")
+		fw.Write([]byte(fileContent))
+		fw, _ = w.CreateFormFile("files", "b.txt")
+		fw.Write([]byte("b"))
+		fw, _ = w.CreateFormFile("files", "c.txt")
+		fw.Write([]byte("c"))
+		w.Close()
+
+		rec := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, path, &body)
+		r.Header.Set("Content-Type", w.FormDataContentType())
+		engine.ServeHTTP(rec, r)
+		return rec.Code
+	}
+
+	// in memory
+	if c := serve("/upload/12?tag=q", "small"); c != http.StatusOK {
+		t.Fatalf("expected 200, got %v", c)
+	}
+	if got.Id != 12 || got.Name != "miso" || got.Tag != "q" {
+		t.Fatalf("unexpected request: %+v", got)
+	}
+	if got.File == nil || got.File.Filename != "a.txt" || got.File.Size != 5 || content != "small" || tmpPath != "" {
+		t.Fatalf("unexpected file: %v, content: %v, tmpPath: %v", got.File, content, tmpPath)
+	}
+	if len(got.Files) != 2 || got.Files[0].Filename != "b.txt" || got.Files[1].Filename != "c.txt" {
+		t.Fatalf("unexpected files: %v", got.Files)
+	}
+
+	// larger than max-memory, saved to temp file and removed after the request
+	large := strings.Repeat("x", 64)
+	if c := serve("/upload/13", large); c != http.StatusOK {
+		t.Fatalf("expected 200, got %v", c)
+	}
+	if content != large || tmpPath == "" {
+		t.Fatalf("unexpected content: %v, tmpPath: %v", content, tmpPath)
+	}
+	if _, err := os.Stat(tmpPath); !os.IsNotExist(err) {
+		t.Fatalf("temp file is not removed, %v", err)
+	}
+
+	// invalid path parameter
+	if c := serve("/upload/abc", "small"); c != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %v", c)
+	}
+
+	// exceeds MaxUploadSize
+	if c := serve("/limited/14", strings.Repeat("x", 256)); c != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413, got %v", c)
+	}
+}
@@ -0,0 +1,267 @@
+package miso
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/curtisnewbie/miso/util/hash"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	outlierBuckets = 10
+)
+
+var (
+	// outlier detectors keyed by 'service/host:port'.
+	outlierDetectors = hash.NewStrRWMap[*outlierDetector]()
+
+	outlierEjectionCounter = sync.OnceValue(func() *prometheus.CounterVec {
+		return NewPromCounterVec("miso_client_outlier_ejections_total", []string{"service", "instance"})
+	})
+	outlierEjectedGauge = sync.OnceValue(func() *prometheus.GaugeVec {
+		return NewPromGaugeVec("miso_client_outlier_ejected", []string{"service", "instance"})
+	})
+)
+
+// Ejected instance, see 'service-discovery.outlier-detection.enabled'.
+type OutlierEjection struct {
+	Service   string
+	Instance  string // 'host:port' of the instance
+	Ejections int    // number of times the instance is ejected consecutively
+	Until     time.Time
+}
+
+// List instances that are currently ejected by outlier detection.
+func OutlierEjections() []OutlierEjection {
+	keys := outlierDetectors.Keys()
+	sort.Strings(keys)
+	ejections := make([]OutlierEjection, 0)
+	now := time.Now()
+	for _, k := range keys {
+		if od, ok := outlierDetectors.Get(k); ok {
+			if until, ejected := od.ejectedUntil(now); ejected {
+				od.mu.Lock()
+				n := od.ejections
+				od.mu.Unlock()
+				ejections = append(ejections, OutlierEjection{Service: od.service, Instance: od.instance, Ejections: n, Until: until})
+			}
+		}
+	}
+	return ejections
+}
+
+type outlierBucket struct {
+	epoch   int64
+	total   int
+	failed  int
+	latency time.Duration
+}
+
+type outlierDetector struct {
+	mu       sync.Mutex
+	service  string
+	instance string
+
+	consecutiveFailures int
+	failureRate         float64
+	maxLatency          time.Duration
+	minRequests         int
+	bucketSize          time.Duration
+	baseEjectionTime    time.Duration
+	maxEjectionTime     time.Duration
+
+	consecutive int
+	ejections   int
+	ejected     bool
+	until       time.Time
+	buckets     [outlierBuckets]outlierBucket
+}
+
+func newOutlierDetector(service string, instance string) *outlierDetector {
+	return &outlierDetector{
+		service:             service,
+		instance:            instance,
+		consecutiveFailures: GetPropInt(PropSDOutlierDetectionConsecutiveFailures),
+		failureRate:         GetPropFloat(PropSDOutlierDetectionFailureRate),
+		maxLatency:          GetPropDuration(PropSDOutlierDetectionMaxLatency),
+		minRequests:         max(GetPropInt(PropSDOutlierDetectionMinRequests), 1),
+		bucketSize:          max(GetPropDuration(PropSDOutlierDetectionWindow)/outlierBuckets, time.Millisecond),
+		baseEjectionTime:    GetPropDuration(PropSDOutlierDetectionBaseEjectionTime),
+		maxEjectionTime:     GetPropDuration(PropSDOutlierDetectionMaxEjectionTime),
+	}
+}
+
+func getOutlierDetector(service string, instance string) *outlierDetector {
+	created := false
+	od, _ := outlierDetectors.GetElse(service+"/"+instance, func(k string) *outlierDetector {
+		created = true
+		return newOutlierDetector(service, instance)
+	})
+	if created {
+		discModule().watchServerList("outlier-detection", service, func(servers []Server) {
+			evictOutlierDetectors(service, servers)
+		})
+	}
+	return od
+}
+
+// evict outlier detectors of instances that are no longer in the server list.
+func evictOutlierDetectors(service string, servers []Server) {
+	for _, k := range outlierDetectors.Keys() {
+		od, ok := outlierDetectors.Get(k)
+		if !ok || od.service != service {
+			continue
+		}
+		if slices.ContainsFunc(servers, func(s Server) bool { return s.ServerAddress() == od.instance }) {
+			continue
+		}
+		outlierDetectors.Del(k)
+		outlierEjectedGauge().DeleteLabelValues(od.service, od.instance)
+		outlierEjectionCounter().DeleteLabelValues(od.service, od.instance)
+		Debugf("Evicted outlier detector of instance %v, service %v", od.instance, od.service)
+	}
+}
+
+// check whether the instance is ejected, the instance is recovered once the ejection expires.
+func (od *outlierDetector) ejectedUntil(now time.Time) (time.Time, bool) {
+	od.mu.Lock()
+	defer od.mu.Unlock()
+	if !od.ejected {
+		return time.Time{}, false
+	}
+	if now.Before(od.until) {
+		return od.until, true
+	}
+	od.ejected = false
+	od.consecutive = 0
+	od.buckets = [outlierBuckets]outlierBucket{}
+	Infof("Instance %v of service %v is recovered from ejection", od.instance, od.service)
+	outlierEjectedGauge().WithLabelValues(od.service, od.instance).Set(0)
+	return time.Time{}, false
+}
+
+func (od *outlierDetector) record(failed bool, latency time.Duration) {
+	od.mu.Lock()
+	defer od.mu.Unlock()
+	if od.ejected {
+		return // requests sent before the instance is ejected
+	}
+
+	now := time.Now()
+	epoch := now.UnixNano() / int64(od.bucketSize)
+	b := &od.buckets[epoch%outlierBuckets]
+	if b.epoch != epoch {
+		*b = outlierBucket{epoch: epoch}
+	}
+	b.total++
+	b.latency += latency
+	if failed {
+		b.failed++
+		od.consecutive++
+	} else {
+		od.consecutive = 0
+	}
+
+	if od.consecutiveFailures > 0 && od.consecutive >= od.consecutiveFailures {
+		od.eject(now, fmt.Sprintf("%d consecutive failures", od.consecutive))
+		return
+	}
+
+	var total, failures int
+	var latencySum time.Duration
+	for _, b := range od.buckets {
+		if b.epoch > epoch-outlierBuckets {
+			total += b.total
+			failures += b.failed
+			latencySum += b.latency
+		}
+	}
+	if total < od.minRequests {
+		return
+	}
+	if rate := float64(failures) / float64(total); od.failureRate > 0 && rate >= od.failureRate {
+		od.eject(now, fmt.Sprintf("failure rate %.2f", rate))
+		return
+	}
+	if avg := latencySum / time.Duration(total); od.maxLatency > 0 && avg > od.maxLatency {
+		od.eject(now, fmt.Sprintf("average latency %v", avg))
+	}
+}
+
+func (od *outlierDetector) eject(now time.Time, reason string) {
+	// the consecutive ejections are reset if the instance stays healthy for a while after the last ejection
+	if od.ejections > 0 && now.Sub(od.until) > od.maxEjectionTime {
+		od.ejections = 0
+	}
+	od.ejections++
+	d := od.baseEjectionTime * time.Duration(od.ejections)
+	if od.maxEjectionTime > 0 && d > od.maxEjectionTime {
+		d = od.maxEjectionTime
+	}
+	od.ejected = true
+	od.until = now.Add(d)
+	Warnf("Ejected instance %v of service %v for %v (%v), ejections: %d", od.instance, od.service, d, reason, od.ejections)
+	outlierEjectionCounter().WithLabelValues(od.service, od.instance).Inc()
+	outlierEjectedGauge().WithLabelValues(od.service, od.instance).Set(1)
+}
+
+func outlierDetectionEnabled() bool {
+	return GetPropBool(PropSDOutlierDetectionEnabled)
+}
+
+// filter out instances that are ejected by outlier detection.
+//
+// At most 'service-discovery.outlier-detection.max-ejection-percent' of the instances are filtered out, the instances
+// whose ejection expires earlier are kept if necessary, and at least one instance is always kept.
+func filterOutliers(service string, servers []Server) []Server {
+	if !outlierDetectionEnabled() || len(servers) < 1 {
+		return servers
+	}
+
+	type ejectedServer struct {
+		address string
+		until   time.Time
+	}
+	now := time.Now()
+	var ejected []ejectedServer
+	for _, s := range servers {
+		if od, ok := outlierDetectors.Get(service + "/" + s.ServerAddress()); ok {
+			if until, ok := od.ejectedUntil(now); ok {
+				ejected = append(ejected, ejectedServer{address: s.ServerAddress(), until: until})
+			}
+		}
+	}
+	if len(ejected) < 1 {
+		return servers
+	}
+
+	maxEjected := len(servers) * min(max(GetPropInt(PropSDOutlierDetectionMaxEjectionPercent), 0), 100) / 100
+	maxEjected = min(maxEjected, len(servers)-1)
+	if len(ejected) > maxEjected {
+		sort.SliceStable(ejected, func(i, j int) bool { return ejected[i].until.After(ejected[j].until) })
+		ejected = ejected[:maxEjected]
+	}
+	excluded := make(map[string]struct{}, len(ejected))
+	for _, e := range ejected {
+		excluded[e.address] = struct{}{}
+	}
+	filtered := make([]Server, 0, len(servers)-len(excluded))
+	for _, s := range servers {
+		if _, ok := excluded[s.ServerAddress()]; !ok {
+			filtered = append(filtered, s)
+		}
+	}
+	return filtered
+}
+
+// record response of the instance for outlier detection.
+func recordOutlier(service string, instance string, failed bool, latency time.Duration) {
+	if !outlierDetectionEnabled() {
+		return
+	}
+	getOutlierDetector(service, instance).record(failed, latency)
+}
@@ -0,0 +1,122 @@
+package miso
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOutlierDetection(t *testing.T) {
+	SetProp(PropSDOutlierDetectionEnabled, true)
+	SetProp(PropSDOutlierDetectionConsecutiveFailures, 2)
+	SetProp(PropSDOutlierDetectionMaxLatency, "100ms")
+	SetProp(PropSDOutlierDetectionMinRequests, 2)
+	SetProp(PropSDOutlierDetectionBaseEjectionTime, "50ms")
+	defer func() {
+		SetProp(PropSDOutlierDetectionEnabled, false)
+		SetProp(PropSDOutlierDetectionConsecutiveFailures, 5)
+		SetProp(PropSDOutlierDetectionMaxLatency, 0)
+		SetProp(PropSDOutlierDetectionMinRequests, 10)
+		SetProp(PropSDOutlierDetectionBaseEjectionTime, "30s")
+		for _, k := range outlierDetectors.Keys() {
+			outlierDetectors.Del(k)
+		}
+	}()
+
+	service := "outlier-service"
+	servers := []Server{
+		{Address: "10.0.0.1", Port: 8080},
+		{Address: "10.0.0.2", Port: 8080},
+		{Address: "10.0.0.3", Port: 8080},
+		{Address: "10.0.0.4", Port: 8080},
+	}
+
+	recordOutlier(service, servers[0].ServerAddress(), true, time.Millisecond)
+	if v := filterOutliers(service, servers); len(v) != 4 {
+		t.Fatalf("expected 4 servers, got %v", v)
+	}
+	recordOutlier(service, servers[0].ServerAddress(), true, time.Millisecond)
+	if v := filterOutliers(service, servers); len(v) != 3 || v[0].ServerAddress() != servers[1].ServerAddress() {
+		t.Fatalf("expected servers[0] to be ejected, got %v", v)
+	}
+
+	// slow instance
+	recordOutlier(service, servers[1].ServerAddress(), false, 200*time.Millisecond)
+	recordOutlier(service, servers[1].ServerAddress(), false, 200*time.Millisecond)
+	if v := filterOutliers(service, servers); len(v) != 2 {
+		t.Fatalf("expected servers[1] to be ejected, got %v", v)
+	}
+	if v := OutlierEjections(); len(v) != 2 {
+		t.Fatalf("expected 2 ejections, got %v", v)
+	}
+
+	// at most 50% of the instances are ejected
+	recordOutlier(service, servers[2].ServerAddress(), true, time.Millisecond)
+	recordOutlier(service, servers[2].ServerAddress(), true, time.Millisecond)
+	if v := filterOutliers(service, servers); len(v) != 2 {
+		t.Fatalf("expected 2 servers, got %v", v)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if v := filterOutliers(service, servers); len(v) != 4 {
+		t.Fatalf("expected servers to be recovered, got %v", v)
+	}
+
+	// ejection time grows for consecutive ejections
+	recordOutlier(service, servers[0].ServerAddress(), true, time.Millisecond)
+	recordOutlier(service, servers[0].ServerAddress(), true, time.Millisecond)
+	time.Sleep(60 * time.Millisecond)
+	if v := filterOutliers(service, servers); len(v) != 3 {
+		t.Fatalf("expected servers[0] to be ejected, got %v", v)
+	}
+	time.Sleep(60 * time.Millisecond)
+	if v := filterOutliers(service, servers); len(v) != 4 {
+		t.Fatalf("expected servers to be recovered, got %v", v)
+	}
+}
+
+func TestOutlierDetectorEviction(t *testing.T) {
+	SetProp(PropSDOutlierDetectionEnabled, true)
+	SetProp(PropSDOutlierDetectionConsecutiveFailures, 1)
+	m := discModule()
+	prev := m.getServerList
+	servers := []Server{{Address: "10.0.0.1", Port: 8080}, {Address: "10.0.0.2", Port: 8080}}
+	ChangeGetServerList(func() ServerList { return staticServerList{servers: servers} })
+	defer func() {
+		SetProp(PropSDOutlierDetectionEnabled, false)
+		SetProp(PropSDOutlierDetectionConsecutiveFailures, 5)
+		ChangeGetServerList(prev)
+		for _, k := range outlierDetectors.Keys() {
+			outlierDetectors.Del(k)
+		}
+	}()
+
+	service := "outlier-evict-service"
+	recordOutlier(service, "10.0.0.1:8080", true, time.Millisecond)
+	recordOutlier(service, "10.0.0.2:8080", false, time.Millisecond)
+	if len(OutlierEjections()) != 1 {
+		t.Fatalf("unexpected ejections: %+v", OutlierEjections())
+	}
+
+	servers = servers[1:]
+	TriggerServerChangeListeners(service)
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, ok := outlierDetectors.Get(service + "/10.0.0.1:8080"); !ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("outlier detector of removed instance is not evicted")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if _, ok := outlierDetectors.Get(service + "/10.0.0.2:8080"); !ok {
+		t.Fatal("outlier detector of remaining instance is evicted")
+	}
+	if len(OutlierEjections()) != 0 {
+		t.Fatalf("unexpected ejections: %+v", OutlierEjections())
+	}
+	if outlierEjectedGauge().DeleteLabelValues(service, "10.0.0.1:8080") {
+		t.Fatal("gauge of removed instance is not deleted")
+	}
+}
@@ -53,3 +53,39 @@ func RespPage(reqPage Paging, total int) Paging {
 		Total: total,
 	}
 }
+
+// Cursor (keyset) pagination parameters, see [EncodeCursor].
+type CursorPaging struct {
+	Limit   int    `json:"limit" desc:"page limit"`
+	Cursor  string `json:"cursor" desc:"opaque cursor; in request, cursor of the page to fetch (empty for the first page); in response, cursor of the next page"`
+	HasMore bool   `json:"hasMore" desc:"whether there are more pages"`
+}
+
+type CursorPageReq struct {
+	Paging CursorPaging `json:"paging" desc:"cursor pagination parameters"`
+}
+
+type CursorPageRes[T any] struct {
+	Page    CursorPaging `json:"paging" desc:"cursor pagination parameters"`
+	Payload []T          `json:"payload" desc:"payload values in current page"`
+}
+
+// Get page limit, the limit is clamped to 'server.pagination.cursor-max-limit'.
+func (p CursorPaging) GetLimit() int {
+	if p.Limit < 1 {
+		p.Limit = DefaultPageLimit
+	}
+	if m := GetPropInt(PropServerPaginationCursorMaxLimit); m > 0 && p.Limit > m {
+		p.Limit = m
+	}
+	return p.Limit
+}
+
+/* Build CursorPaging for response */
+func RespCursorPage(reqPage CursorPaging, nextCursor string) CursorPaging {
+	return CursorPaging{
+		Limit:   reqPage.GetLimit(),
+		Cursor:  nextCursor,
+		HasMore: nextCursor != "",
+	}
+}
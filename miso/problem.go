@@ -0,0 +1,142 @@
+package miso
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+
+	"github.com/curtisnewbie/miso/errs"
+	"github.com/curtisnewbie/miso/util/json"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	ContentTypeProblemJson = "application/problem+json"
+
+	// default problem type, see RFC 7807 4.2.
+	ProblemTypeBlank = "about:blank"
+
+	ctxKeyProblemJson = "miso-ProblemJson"
+)
+
+// RFC 7807 problem details, errors are rendered as `application/problem+json` using [ProblemResultBodyBuilder].
+type ProblemDetail struct {
+	Type     string `json:"type" desc:"problem type"`
+	Title    string `json:"title" desc:"short summary of the problem type"`
+	Status   int    `json:"status" desc:"http status"`
+	Detail   string `json:"detail,omitempty" desc:"explanation of the problem"`
+	Instance string `json:"instance,omitempty" desc:"request uri"`
+
+	// extensions
+
+	Code        string              `json:"code,omitempty" desc:"error code"`
+	TraceId     string              `json:"traceId,omitempty" desc:"trace id"`
+	InternalMsg string              `json:"internalMsg,omitempty" desc:"internal message, only available in non-prod mode with debug log level"`
+	Errors      []ProblemFieldError `json:"errors,omitempty" desc:"validation errors"`
+}
+
+// Validation error of a request field.
+type ProblemFieldError struct {
+	Field string `json:"field" desc:"name of the field"`
+	Rule  string `json:"rule" desc:"validation rule violated"`
+	Msg   string `json:"msg" desc:"validation message"`
+}
+
+// Convert problem to MisoErr.
+func (p ProblemDetail) Err() *MisoErr {
+	code := p.Code
+	if code == "" {
+		code = ErrCodeGeneric
+	}
+	msg := p.Detail
+	if msg == "" {
+		msg = p.Title
+	}
+	return errs.NewErrfCode(code, msg).WithHttpStatus(p.Status)
+}
+
+// ResultBodyBuilder that renders errors as RFC 7807 `application/problem+json`, successful responses are still wrapped in [Resp].
+//
+// Use it for the whole app with [SetResultBodyBuilder], or for specific routes with [LazyRouteDecl.ProblemJson].
+func ProblemResultBodyBuilder() ResultBodyBuilder {
+	return ResultBodyBuilder{
+		ErrJsonBuilder:     func(rail Rail, url string, err error) any { return NewProblemDetail(rail, url, err) },
+		PayloadJsonBuilder: func(payload any) any { return OkRespWData(payload) },
+		OkJsonBuilder:      func() any { return OkResp() },
+	}
+}
+
+// Build ProblemDetail from error.
+//
+// Code and msg are resolved using [WrapResp], the http status is resolved as follows:
+//
+//   - http status of the MisoErr, or 400 Bad Request if it's absent.
+//   - 400 Bad Request for [ValidationError].
+//   - 500 Internal Server Error for unknown errors.
+//
+// Field errors of [ValidationError] are included even if it's wrapped by MisoErr.
+func NewProblemDetail(rail Rail, url string, err error) ProblemDetail {
+	r := WrapResp(rail, nil, err, url)
+	p := ProblemDetail{
+		Type:     ProblemTypeBlank,
+		Status:   http.StatusInternalServerError,
+		Detail:   r.Msg,
+		Instance: url,
+		Code:     r.ErrorCode,
+		TraceId:  rail.TraceId(),
+	}
+
+	me, isMisoErr := errs.As[*MisoErr](err)
+	if isMisoErr {
+		p.Status = http.StatusBadRequest
+		if me.HttpStatus() > 0 {
+			p.Status = me.HttpStatus()
+		}
+		if !IsProdMode() && IsDebugLevel() {
+			p.InternalMsg = me.InternalMsg()
+		}
+	}
+	// ValidationError may be wrapped by MisoErr, e.g., errs.Wrap(ve)
+	if ve := (&ValidationError{}); errors.As(err, &ve) {
+		if !isMisoErr {
+			p.Status = http.StatusBadRequest
+		}
+		p.Errors = []ProblemFieldError{{Field: ve.Field, Rule: ve.Rule, Msg: ve.Error()}}
+	}
+	p.Title = http.StatusText(p.Status)
+	return p
+}
+
+// Render errors of the endpoint as RFC 7807 `application/problem+json`, see [ProblemResultBodyBuilder].
+func (g *LazyRouteDecl) ProblemJson() *LazyRouteDecl {
+	return g.intercept(func(c *gin.Context, next func()) {
+		c.Set(ctxKeyProblemJson, true)
+		next()
+	})
+}
+
+func dispatchProblem(c *gin.Context, p ProblemDetail) {
+	c.Status(p.Status)
+	c.Header("Content-Type", ContentTypeProblemJson)
+	if err := json.EncodeJson(c.Writer, p); err != nil {
+		panic(err)
+	}
+}
+
+// check whether the response is RFC 7807 problem details.
+func isProblemResp(r *http.Response) bool {
+	if r == nil {
+		return false
+	}
+	mt, _, err := mime.ParseMediaType(r.Header.Get(contentType))
+	return err == nil && mt == ContentTypeProblemJson
+}
+
+// parse RFC 7807 problem details as MisoErr.
+func parseProblemErr(body []byte) error {
+	var p ProblemDetail
+	if err := json.ParseJson(body, &p); err != nil {
+		return errs.Wrapf(err, "failed to unmarshal problem details from response, body: %s", body)
+	}
+	return p.Err()
+}
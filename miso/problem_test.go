@@ -0,0 +1,98 @@
+package miso
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/curtisnewbie/miso/errs"
+	"github.com/curtisnewbie/miso/util/json"
+	"github.com/gin-gonic/gin"
+)
+
+type problemTestReq struct {
+	Name string `json:"name" valid:"notEmpty"`
+}
+
+func TestProblemJson(t *testing.T) {
+	prevRoutes, prevInterceptors := lazyRouteRegistars, interceptors
+	defer func() { lazyRouteRegistars, interceptors = prevRoutes, prevInterceptors }()
+	gin.SetMode(gin.TestMode)
+
+	errNotFound := errs.NewErrfCode("ORDER_NOT_FOUND", "Order not found").WithHttpStatus(http.StatusNotFound)
+	failed := RawHandler(func(inb *Inbound) { inb.HandleResult(nil, errNotFound.New()) })
+	engine := gin.New()
+	engine.Use(TraceMiddleware())
+	for _, decl := range []*LazyRouteDecl{
+		HttpGet("/problem", failed).ProblemJson(),
+		HttpGet("/default", failed),
+		HttpPost("/validate", AutoHandler(func(inb *Inbound, req problemTestReq) (any, error) { return nil, nil })).ProblemJson(),
+	} {
+		engine.Handle(decl.Method, decl.Url, decl.Handler)
+	}
+	server := httptest.NewServer(engine)
+	defer server.Close()
+
+	rail := EmptyRail()
+	tr := NewClient(rail, server.URL+"/problem").AddHeader(XTraceId, "test-trace").Get()
+	if ct := tr.Resp.Header.Get("Content-Type"); ct != ContentTypeProblemJson {
+		t.Fatalf("unexpected content type: %v", ct)
+	}
+	var p ProblemDetail
+	body, err := tr.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.ParseJson(body, &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Status != http.StatusNotFound || p.Title != "Not Found" || p.Type != ProblemTypeBlank || p.Code != "ORDER_NOT_FOUND" ||
+		p.Detail != "Order not found" || p.Instance != "/problem" || p.TraceId != "test-trace" {
+		t.Fatalf("unexpected problem: %+v", p)
+	}
+
+	// parsed back into MisoErr
+	var res GnResp[any]
+	err = NewClient(rail, server.URL+"/problem").Get().Json(&res)
+	me, ok := errs.As[*MisoErr](err)
+	if !ok || me.Code() != "ORDER_NOT_FOUND" || me.Msg() != "Order not found" || me.HttpStatus() != http.StatusNotFound {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// other routes are not affected
+	tr = NewClient(rail, server.URL+"/default").Get()
+	if ct := tr.Resp.Header.Get("Content-Type"); ct == ContentTypeProblemJson {
+		t.Fatalf("unexpected content type: %v", ct)
+	}
+	err = tr.Json(&res)
+	if me, ok := errs.As[*MisoErr](err); !ok || me.Code() != "ORDER_NOT_FOUND" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// validation errors
+	body, err = NewClient(rail, server.URL+"/validate").PostJson(problemTestReq{}).Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p = ProblemDetail{}
+	if err := json.ParseJson(body, &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Status != http.StatusBadRequest || len(p.Errors) != 1 || p.Errors[0].Field != "Name" || p.Errors[0].Rule != "notEmpty" {
+		t.Fatalf("unexpected problem: %+v", p)
+	}
+}
+
+func TestNewProblemDetailWrappedValidationError(t *testing.T) {
+	ve := &ValidationError{Field: "name", Rule: "notEmpty", ValidationMsg: "must not be empty"}
+	p := NewProblemDetail(EmptyRail(), "/validate", errs.Wrapf(ve, "failed to validate request"))
+	if p.Status != http.StatusBadRequest || len(p.Errors) != 1 || p.Errors[0].Field != "name" || p.Errors[0].Rule != "notEmpty" {
+		t.Fatalf("unexpected problem: %+v", p)
+	}
+
+	// http status of the MisoErr is kept
+	p = NewProblemDetail(EmptyRail(), "/validate", errs.NewErrf("Invalid order").WithHttpStatus(http.StatusUnprocessableEntity).Wrap(ve))
+	if p.Status != http.StatusUnprocessableEntity || len(p.Errors) != 1 || p.Errors[0].Field != "name" {
+		t.Fatalf("unexpected problem: %+v", p)
+	}
+}
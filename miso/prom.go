@@ -116,6 +116,28 @@ func NewPromCounter(name string) prometheus.Counter {
 	return counter
 }
 
+// Create new CounterVec.
+//
+// The CounterVec is automatically registered to the prometheus.DefaultRegisterer.
+func NewPromCounterVec(name string, labels []string) *prometheus.CounterVec {
+	vec := prometheus.NewCounterVec(prometheus.CounterOpts{Name: name}, labels)
+	if e := prometheus.DefaultRegisterer.Register(vec); e != nil {
+		panic(fmt.Errorf("failed to register CounterVec %v, %w", name, e))
+	}
+	return vec
+}
+
+// Create new GaugeVec.
+//
+// The GaugeVec is automatically registered to the prometheus.DefaultRegisterer.
+func NewPromGaugeVec(name string, labels []string) *prometheus.GaugeVec {
+	vec := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: name}, labels)
+	if e := prometheus.DefaultRegisterer.Register(vec); e != nil {
+		panic(fmt.Errorf("failed to register GaugeVec %v, %w", name, e))
+	}
+	return vec
+}
+
 func prometheusBootstrapCondition(rail Rail) (bool, error) {
 	return GetPropBool(PropMetricsEnabled) && GetPropBool(PropServerEnabled), nil
 }
@@ -136,7 +158,8 @@ func prometheusBootstrap(rail Rail) error {
 		HttpGet(metricsRoute,
 			RawHandler(func(inb *Inbound) { handler.ServeHTTP(inb.Unwrap()) })).
 			Desc("Collect prometheus metrics information").
-			DocHeader("Authorization", "Basic authorization if enabled")
+			DocHeader("Authorization", "Basic authorization if enabled").
+			Admin()
 	}
 	return nil
 }
@@ -89,6 +89,148 @@ const (
 
 	// misoconfig-prop: disable gin's builtin validation | true
 	PropServerGinValidationDisabled = "server.gin.validation.disabled"
+
+	// misoconfig-prop: enable response compression, encoding is negotiated using `Accept-Encoding` header | false
+	PropServerCompressionEnabled = "server.compression.enabled"
+
+	// misoconfig-prop: minimum size (in bytes) of response to be compressed | 1024
+	PropServerCompressionMinSize = "server.compression.min-size"
+
+	// misoconfig-prop: content types (slice of strings) of response to be compressed | `[]string{"application/json", "application/javascript", "application/xml", "text/plain", "text/html", "text/css", "text/xml", "text/csv"}`
+	PropServerCompressionContentTypes = "server.compression.content-types"
+
+	// misoconfig-prop: supported encodings (slice of strings) in the order of preference, `br` and `gzip` are supported | `[]string{"br", "gzip"}`
+	PropServerCompressionEncodings = "server.compression.encodings"
+
+	// misoconfig-prop: max size (in bytes) of multipart request body, it can be overriden for each endpoint using `LazyRouteDecl.MaxUploadSize(..)`, 32MB by default | 33554432
+	PropServerMultipartMaxSize = "server.multipart.max-size"
+
+	// misoconfig-prop: max size (in bytes) of uploaded file that is kept in memory, larger files are saved to temp files, 1MB by default | 1048576
+	PropServerMultipartMaxMemory = "server.multipart.max-memory"
+
+	// misoconfig-prop: interval of websocket ping messages | 30s
+	PropServerWebSocketPingInterval = "server.websocket.ping-interval"
+
+	// misoconfig-prop: websocket read deadline, connection is closed if no message (including pong) is received within the duration | 60s
+	PropServerWebSocketReadTimeout = "server.websocket.read-timeout"
+
+	// misoconfig-prop: websocket write deadline | 10s
+	PropServerWebSocketWriteTimeout = "server.websocket.write-timeout"
+
+	// misoconfig-prop: max size (in bytes) of websocket message received | 1048576
+	PropServerWebSocketMaxMessageSize = "server.websocket.max-message-size"
+
+	// misoconfig-prop: allowed origins (slice of strings) of websocket upgrade requests, `*` allows all origins; by default, only requests from the same host are allowed |
+	PropServerWebSocketAllowedOrigins = "server.websocket.allowed-origins"
+
+	// misoconfig-prop: enforce route Scope and Resource using the in-process authorizer, see `miso.SetPermissionChecker(..)` | false
+	PropServerAuthorizationEnabled = "server.authorization.enabled"
+
+	// misoconfig-prop: ttl of cached permission decisions | 30s
+	PropServerAuthorizationCacheTtl = "server.authorization.cache-ttl"
+
+	// misoconfig-prop: max number of cached permission decisions | 10000
+	PropServerAuthorizationCacheSize = "server.authorization.cache-size"
+
+	// misoconfig-prop: enable CORS policy for all endpoints, it can be overriden for RoutingGroup using `RoutingGroup.Cors(..)` | false
+	PropServerCorsEnabled = "server.cors.enabled"
+
+	// misoconfig-prop: allowed origin patterns (slice of strings), e.g., `https://*.example.com`; `*` allows all origins | `[]string{"*"}`
+	PropServerCorsAllowedOrigins = "server.cors.allowed-origins"
+
+	// misoconfig-prop: allowed methods (slice of strings) | `[]string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD"}`
+	PropServerCorsAllowedMethods = "server.cors.allowed-methods"
+
+	// misoconfig-prop: allowed request headers (slice of strings), `*` allows all headers requested | `[]string{"*"}`
+	PropServerCorsAllowedHeaders = "server.cors.allowed-headers"
+
+	// misoconfig-prop: response headers (slice of strings) exposed to the browser |
+	PropServerCorsExposedHeaders = "server.cors.exposed-headers"
+
+	// misoconfig-prop: allow credentials (cookies, authorization headers, etc), the matched request origin is echoed instead of `*`, can't be used with allowed origin `*` | false
+	PropServerCorsAllowCredentials = "server.cors.allow-credentials"
+
+	// misoconfig-prop: how long the preflight results can be cached | 1h
+	PropServerCorsMaxAge = "server.cors.max-age"
+
+	// misoconfig-prop: secret used to sign cursor tokens of cursor pagination, if absent, a random secret is generated and cursors are only valid for the current process |
+	PropServerPaginationCursorSecret = "server.pagination.cursor-secret"
+
+	// misoconfig-prop: max page limit of cursor pagination, limit requested by the client is clamped to it | 1000
+	PropServerPaginationCursorMaxLimit = "server.pagination.cursor-max-limit"
+
+	// misoconfig-prop: timeout of each inbound request, the Rail of the handler is cancelled once the timeout is exceeded, it can be overriden for each endpoint using `LazyRouteDecl.Timeout(..)`, it's not applied to streaming routes (WebSocket and SSE); 0 means no timeout | 0
+	PropServerRequestTimeout = "server.request.timeout"
+
+	// misoconfig-prop: max size (in bytes) of request body (excluding multipart requests), it can be overriden for each endpoint using `LazyRouteDecl.MaxBodySize(..)`; 0 means no limit | 0
+	PropServerRequestMaxBodySize = "server.request.max-body-size"
+
+	// misoconfig-prop: enable graceful restart on SIGHUP (or `POST /debug/restart` on admin http server), the new process inherits the listeners, only supported on linux | false
+	PropServerGracefulRestartEnabled = "server.graceful-restart.enabled"
+
+	// misoconfig-prop: how long to wait for the new process to be ready, the new process is killed if it's not ready in time | 60s
+	PropServerGracefulRestartReadyTimeout = "server.graceful-restart.ready-timeout"
+
+	// misoconfig-prop: enable structured access log | false
+	PropServerAccessLogEnabled = "server.access-log.enabled"
+
+	// misoconfig-prop: access log format, one of: `json` (JSON lines), `combined` (Apache combined log format) | json
+	PropServerAccessLogFormat = "server.access-log.format"
+
+	// misoconfig-prop: path to the rolling access log file, `logging.file.max-size`, `logging.file.max-age` and `logging.file.max-backups` also apply; by default, access log is written to the app log output |
+	PropServerAccessLogFile = "server.access-log.file"
+
+	// misoconfig-prop: sample rate (0 to 1) of access log, it can be overriden for each endpoint using `LazyRouteDecl.AccessLogSampleRate(..)`; 5xx responses are always logged | 1
+	PropServerAccessLogSampleRate = "server.access-log.sample-rate"
+
+	// misoconfig-prop: include request headers in access log | false
+	PropServerAccessLogHeaders = "server.access-log.headers"
+
+	// misoconfig-prop: request headers (slice of strings) that are redacted in access log | `[]string{"Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}`
+	PropServerAccessLogRedactedHeaders = "server.access-log.redacted-headers"
+
+	// misoconfig-prop: include request and response bodies in access log (JSON format only) | false
+	PropServerAccessLogBodies = "server.access-log.bodies"
+
+	// misoconfig-prop: max size (in bytes) of request and response bodies included in access log, bodies are truncated | 4096
+	PropServerAccessLogMaxBodySize = "server.access-log.max-body-size"
+
+	// misoconfig-prop: enable TLS, the server serves HTTPS | false
+	PropServerTlsEnabled = "server.tls.enabled"
+
+	// misoconfig-prop: path to the PEM encoded server certificate
+	PropServerTlsCertFile = "server.tls.cert-file"
+
+	// misoconfig-prop: path to the PEM encoded server private key
+	PropServerTlsKeyFile = "server.tls.key-file"
+
+	// misoconfig-prop: path to the PEM encoded CA certificates that are used to verify client certificates (mTLS)
+	PropServerTlsClientCaFile = "server.tls.client-ca-file"
+
+	// misoconfig-prop: client certificate policy, one of: `none`, `request`, `require`, `verify-if-given`, `require-and-verify` | none
+	PropServerTlsClientAuth = "server.tls.client-auth"
+
+	// misoconfig-prop: minimum TLS version, one of: `1.0`, `1.1`, `1.2`, `1.3` | 1.2
+	PropServerTlsMinVersion = "server.tls.min-version"
+
+	// misoconfig-prop: interval of checking whether certificate files are changed on disk, changed files are reloaded | 30s
+	PropServerTlsReloadInterval = "server.tls.reload-interval"
+
+	// misoconfig-prop: enable admin http server, the health check, metrics, pprof and job trigger endpoints are served on the admin http server instead | false
+	PropServerAdminEnabled = "server.admin.enabled"
+
+	// misoconfig-prop: admin http server host | 127.0.0.1
+	PropServerAdminHost = "server.admin.host"
+
+	// misoconfig-prop: admin http server port, '0' means select any port that can be used | 8081
+	PropServerAdminPort = "server.admin.port"
+
+	// misoconfig-prop: admin http server actual port used, read-only, do not overwrite it.
+	// misoconfig-doc-only
+	PropServerAdminActualPort = "server.admin.actual-port"
+
+	// misoconfig-prop: admin http server bearer authorization token for all admin endpoints |
+	PropServerAdminAuthBearer = "server.admin.auth.bearer"
 )
 
 // misoconfig-section: Consul Configuration
@@ -134,6 +276,79 @@ const (
 
 	// misoconfig-prop: slice of service names that should be subcribed on startup
 	PropSDSubscrbe = "service-discovery.subscribe"
+
+	// misoconfig-prop: default load balancing strategy, one of 'random', 'round-robin', 'weighted-round-robin', 'least-requests' and 'consistent-hash', can be overriden for specific service using 'service-discovery.lb.${SERVICE_NAME}' | random
+	PropSDDefaultLb = "service-discovery.default-lb"
+
+	// misoconfig-prop: enable passive outlier detection, unhealthy instances are temporarily ejected based on the responses of requests sent by Client | false
+	PropSDOutlierDetectionEnabled = "service-discovery.outlier-detection.enabled"
+
+	// misoconfig-prop: eject the instance after the number of consecutive failures (network errors or 5xx responses) | 5
+	PropSDOutlierDetectionConsecutiveFailures = "service-discovery.outlier-detection.consecutive-failures"
+
+	// misoconfig-prop: eject the instance when the failure rate (0 to 1) within the sliding window reaches the threshold | 0.5
+	PropSDOutlierDetectionFailureRate = "service-discovery.outlier-detection.failure-rate"
+
+	// misoconfig-prop: eject the instance when the average latency within the sliding window exceeds the threshold, disabled if it's 0 | 0
+	PropSDOutlierDetectionMaxLatency = "service-discovery.outlier-detection.max-latency"
+
+	// misoconfig-prop: min number of requests within the sliding window before the failure rate and the average latency are evaluated | 10
+	PropSDOutlierDetectionMinRequests = "service-discovery.outlier-detection.min-requests"
+
+	// misoconfig-prop: duration of the sliding window | 10s
+	PropSDOutlierDetectionWindow = "service-discovery.outlier-detection.window"
+
+	// misoconfig-prop: base ejection duration, the instance is ejected for base-ejection-time multiplied by the number of times it's ejected consecutively | 30s
+	PropSDOutlierDetectionBaseEjectionTime = "service-discovery.outlier-detection.base-ejection-time"
+
+	// misoconfig-prop: max ejection duration | 5m
+	PropSDOutlierDetectionMaxEjectionTime = "service-discovery.outlier-detection.max-ejection-time"
+
+	// misoconfig-prop: max percentage (0 to 100) of instances of a service that can be ejected at the same time, at least one instance is always available | 50
+	PropSDOutlierDetectionMaxEjectionPercent = "service-discovery.outlier-detection.max-ejection-percent"
+)
+
+// misoconfig-section: HTTP Client Configuration
+const (
+
+	// misoconfig-prop: enable client TLS, requests to services resolved using service discovery are sent with HTTPS | false
+	PropClientTlsEnabled = "client.tls.enabled"
+
+	// misoconfig-prop: path to the PEM encoded client certificate presented to the servers (mTLS)
+	PropClientTlsCertFile = "client.tls.cert-file"
+
+	// misoconfig-prop: path to the PEM encoded client private key
+	PropClientTlsKeyFile = "client.tls.key-file"
+
+	// misoconfig-prop: path to the PEM encoded CA certificates that are used to verify server certificates (in addition to the system CAs)
+	PropClientTlsCaFile = "client.tls.ca-file"
+
+	// misoconfig-prop: interval of checking whether certificate files are changed on disk, changed files are reloaded | 30s
+	PropClientTlsReloadInterval = "client.tls.reload-interval"
+
+	// misoconfig-prop: enable circuit breaker for requests sent to services resolved using service discovery | false
+	PropClientCircuitBreakerEnabled = "client.circuit-breaker.enabled"
+
+	// misoconfig-prop: open the circuit after the number of consecutive failures | 5
+	PropClientCircuitBreakerConsecutiveFailures = "client.circuit-breaker.consecutive-failures"
+
+	// misoconfig-prop: open the circuit when the failure rate (0 to 1) within the sliding window reaches the threshold | 0.5
+	PropClientCircuitBreakerFailureRate = "client.circuit-breaker.failure-rate"
+
+	// misoconfig-prop: min number of requests within the sliding window before the failure rate is evaluated | 20
+	PropClientCircuitBreakerMinRequests = "client.circuit-breaker.min-requests"
+
+	// misoconfig-prop: duration of the sliding window | 10s
+	PropClientCircuitBreakerWindow = "client.circuit-breaker.window"
+
+	// misoconfig-prop: how long the circuit stays open before it becomes half-open | 30s
+	PropClientCircuitBreakerOpenDuration = "client.circuit-breaker.open-duration"
+
+	// misoconfig-prop: max number of concurrent probe requests in half-open state | 1
+	PropClientCircuitBreakerHalfOpenRequests = "client.circuit-breaker.half-open-requests"
+
+	// misoconfig-prop: register HealthIndicator that reports unhealthy while the circuit of any service is open | false
+	PropClientCircuitBreakerHealthIndicator = "client.circuit-breaker.health-indicator"
 )
 
 // misoconfig-section: Tracing Configuration
@@ -262,6 +477,16 @@ func init() {
 	SetDefProp(PropConsulFetchServerInterval, 30)
 	SetDefProp(PropConsulEnableDeregisterUrl, false)
 	SetDefProp(PropConsulDeregisterUrl, "/consul/deregister")
+	SetDefProp(PropClientTlsEnabled, false)
+	SetDefProp(PropClientTlsReloadInterval, "30s")
+	SetDefProp(PropClientCircuitBreakerEnabled, false)
+	SetDefProp(PropClientCircuitBreakerConsecutiveFailures, 5)
+	SetDefProp(PropClientCircuitBreakerFailureRate, "0.5")
+	SetDefProp(PropClientCircuitBreakerMinRequests, 20)
+	SetDefProp(PropClientCircuitBreakerWindow, "10s")
+	SetDefProp(PropClientCircuitBreakerOpenDuration, "30s")
+	SetDefProp(PropClientCircuitBreakerHalfOpenRequests, 1)
+	SetDefProp(PropClientCircuitBreakerHealthIndicator, false)
 	SetDefProp(PropSchedApiTriggerJobEnabled, false)
 	SetDefProp(PropLoggingLevel, "info")
 	SetDefProp(PropLoggingRollingFileAppendIpSuffix, false)
@@ -279,6 +504,16 @@ func init() {
 	SetDefProp(PropMetricsPushGatewayJob, "${app.name}")
 	SetDefProp(PropMetricsPushGatewayIntervalSec, 30)
 	SetDefProp(PropMetricsPushGatewayAuthEnabled, false)
+	SetDefProp(PropSDDefaultLb, "random")
+	SetDefProp(PropSDOutlierDetectionEnabled, false)
+	SetDefProp(PropSDOutlierDetectionConsecutiveFailures, 5)
+	SetDefProp(PropSDOutlierDetectionFailureRate, "0.5")
+	SetDefProp(PropSDOutlierDetectionMaxLatency, 0)
+	SetDefProp(PropSDOutlierDetectionMinRequests, 10)
+	SetDefProp(PropSDOutlierDetectionWindow, "10s")
+	SetDefProp(PropSDOutlierDetectionBaseEjectionTime, "30s")
+	SetDefProp(PropSDOutlierDetectionMaxEjectionTime, "5m")
+	SetDefProp(PropSDOutlierDetectionMaxEjectionPercent, 50)
 	SetDefProp(PropServerEnabled, true)
 	SetDefProp(PropServerHost, "127.0.0.1")
 	SetDefProp(PropServerPort, 8080)
@@ -295,6 +530,44 @@ func init() {
 	SetDefProp(PropServerPprofEnabled, false)
 	SetDefProp(PropServerRequestAutoMapHeader, true)
 	SetDefProp(PropServerGinValidationDisabled, true)
+	SetDefProp(PropServerCompressionEnabled, false)
+	SetDefProp(PropServerCompressionMinSize, 1024)
+	SetDefProp(PropServerCompressionContentTypes, []string{"application/json", "application/javascript", "application/xml", "text/plain", "text/html", "text/css", "text/xml", "text/csv"})
+	SetDefProp(PropServerCompressionEncodings, []string{"br", "gzip"})
+	SetDefProp(PropServerMultipartMaxSize, 33554432)
+	SetDefProp(PropServerMultipartMaxMemory, 1048576)
+	SetDefProp(PropServerWebSocketPingInterval, "30s")
+	SetDefProp(PropServerWebSocketReadTimeout, "60s")
+	SetDefProp(PropServerWebSocketWriteTimeout, "10s")
+	SetDefProp(PropServerWebSocketMaxMessageSize, 1048576)
+	SetDefProp(PropServerAuthorizationEnabled, false)
+	SetDefProp(PropServerAuthorizationCacheTtl, "30s")
+	SetDefProp(PropServerAuthorizationCacheSize, 10000)
+	SetDefProp(PropServerCorsEnabled, false)
+	SetDefProp(PropServerCorsAllowedOrigins, []string{"*"})
+	SetDefProp(PropServerCorsAllowedMethods, []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD"})
+	SetDefProp(PropServerCorsAllowedHeaders, []string{"*"})
+	SetDefProp(PropServerCorsAllowCredentials, false)
+	SetDefProp(PropServerCorsMaxAge, "1h")
+	SetDefProp(PropServerPaginationCursorMaxLimit, 1000)
+	SetDefProp(PropServerRequestTimeout, 0)
+	SetDefProp(PropServerRequestMaxBodySize, 0)
+	SetDefProp(PropServerGracefulRestartEnabled, false)
+	SetDefProp(PropServerGracefulRestartReadyTimeout, "60s")
+	SetDefProp(PropServerAccessLogEnabled, false)
+	SetDefProp(PropServerAccessLogFormat, "json")
+	SetDefProp(PropServerAccessLogSampleRate, 1)
+	SetDefProp(PropServerAccessLogHeaders, false)
+	SetDefProp(PropServerAccessLogRedactedHeaders, []string{"Authorization", "Cookie", "Set-Cookie", "X-Api-Key"})
+	SetDefProp(PropServerAccessLogBodies, false)
+	SetDefProp(PropServerAccessLogMaxBodySize, 4096)
+	SetDefProp(PropServerTlsEnabled, false)
+	SetDefProp(PropServerTlsClientAuth, "none")
+	SetDefProp(PropServerTlsMinVersion, "1.2")
+	SetDefProp(PropServerTlsReloadInterval, "30s")
+	SetDefProp(PropServerAdminEnabled, false)
+	SetDefProp(PropServerAdminHost, "127.0.0.1")
+	SetDefProp(PropServerAdminPort, 8081)
 }
 
 // misoconfig-default-end
@@ -199,6 +199,9 @@ func (h *HttpProxy) proxyRequestHandler(inb *Inbound) {
 			return nil
 		}
 
+		if targetUrl, err := url.Parse(path); err == nil && targetUrl.Host != "" {
+			defer lbRequestStarted(targetUrl.Host)()
+		}
 		if err := async.CapturePanicErr(func() {
 			rproxy.ServeHTTP(w, r)
 		}); err != nil {
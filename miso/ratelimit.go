@@ -0,0 +1,185 @@
+package miso
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/curtisnewbie/miso/errs"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	ErrCodeTooManyRequests = "TOO_MANY_REQUESTS"
+)
+
+var (
+	ErrTooManyRequests = errs.NewErrfCode(ErrCodeTooManyRequests, "Too Many Requests").WithHttpStatus(http.StatusTooManyRequests)
+)
+
+var (
+	routeRateLimiterFactory RouteRateLimiterFactory = func(name string, max int, period time.Duration) RouteRateLimiter {
+		return NewLocalRateLimiter(max, period)
+	}
+)
+
+// Rate limiter used by route declared with [LazyRouteDecl.RateLimit].
+type RouteRateLimiter interface {
+
+	// Try to acquire a permit for the key.
+	//
+	// If the permit is not acquired, retryAfter tells the client when it should try again.
+	AcquireKey(rail Rail, key string) (ok bool, retryAfter time.Duration, err error)
+}
+
+// Factory of RouteRateLimiter, name is unique for each route.
+type RouteRateLimiterFactory func(name string, max int, period time.Duration) RouteRateLimiter
+
+// Replace the default RouteRateLimiterFactory, by default, local token-bucket rate limiter is used.
+//
+// E.g., use redis.UseDistributedRouteRateLimiter() to share the rate limit among all instances.
+func SetRouteRateLimiterFactory(f RouteRateLimiterFactory) {
+	if f == nil {
+		panic("RouteRateLimiterFactory is nil")
+	}
+	routeRateLimiterFactory = f
+}
+
+// Extract rate limit key from the inbound request.
+type RateLimitKeyFunc func(inb *Inbound) string
+
+// Rate limit by ip of the remote peer (see [Inbound.RemoteIP]).
+//
+// X-Forwarded-For and X-Real-Ip headers are not trusted, as any client can set them to bypass the rate limit.
+// If the service is behind a proxy, use your own RateLimitKeyFunc that resolves the client ip from the headers set
+// by the trusted proxy.
+func RateLimitByIp(inb *Inbound) string {
+	return "ip:" + inb.RemoteIP()
+}
+
+// Rate limit by flow.User.UserNo, requests without user are rate limited by client ip.
+func RateLimitByUser(inb *Inbound) string {
+	if u := inb.Rail().User(); u.UserNo != "" {
+		return "user:" + u.UserNo
+	}
+	return RateLimitByIp(inb)
+}
+
+// Rate limit the endpoint, each key can only make max number of requests in the period.
+//
+// If keyFunc is nil, [RateLimitByIp] is used.
+//
+// The RouteRateLimiter is created using RouteRateLimiterFactory, see [SetRouteRateLimiterFactory].
+func (g *LazyRouteDecl) RateLimit(max int, period time.Duration, keyFunc RateLimitKeyFunc) *LazyRouteDecl {
+	if max < 1 || period <= 0 {
+		panic(fmt.Errorf("invalid rate limit, max: %v, period: %v", max, period))
+	}
+	limiter := sync.OnceValue(func() RouteRateLimiter { return routeRateLimiterFactory(g.Method+" "+g.Url, max, period) })
+	return g.extra(ExtraRateLimit, fmt.Sprintf("%v requests per %v", max, period), extraFilterOneByKey()).
+		intercept(rateLimitInterceptor(limiter, keyFunc))
+}
+
+// Rate limit the endpoint using the given RouteRateLimiter.
+//
+// If keyFunc is nil, [RateLimitByIp] is used.
+func (g *LazyRouteDecl) RateLimitWith(limiter RouteRateLimiter, keyFunc RateLimitKeyFunc) *LazyRouteDecl {
+	if limiter == nil {
+		panic("RouteRateLimiter is nil")
+	}
+	return g.extra(ExtraRateLimit, "custom", extraFilterOneByKey()).
+		intercept(rateLimitInterceptor(func() RouteRateLimiter { return limiter }, keyFunc))
+}
+
+func rateLimitInterceptor(limiter func() RouteRateLimiter, keyFunc RateLimitKeyFunc) func(c *gin.Context, next func()) {
+	if keyFunc == nil {
+		keyFunc = RateLimitByIp
+	}
+	return func(c *gin.Context, next func()) {
+		inb := newInbound(c)
+		rail := inb.Rail()
+		key := keyFunc(inb)
+		ok, retryAfter, err := limiter().AcquireKey(rail, key)
+		if err != nil {
+			rail.Errorf("Failed to acquire rate limit permit, key: '%v', %v", key, err)
+			inb.HandleResult(nil, err)
+			return
+		}
+		if !ok {
+			rail.Infof("Request '%v %v' rate limited, key: '%v'", c.Request.Method, c.Request.RequestURI, key)
+			if retryAfter > 0 {
+				c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			}
+			inb.HandleResult(nil, ErrTooManyRequests.New())
+			return
+		}
+		next()
+	}
+}
+
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// Local token-bucket rate limiter.
+type localRateLimiter struct {
+	mu        sync.Mutex
+	max       float64
+	period    time.Duration
+	rate      float64 // tokens per nanosecond
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+func (l *localRateLimiter) AcquireKey(rail Rail, key string) (bool, time.Duration, error) {
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: l.max, updated: now}
+		l.buckets[key] = b
+	} else {
+		b.tokens = math.Min(l.max, b.tokens+float64(now.Sub(b.updated))*l.rate)
+		b.updated = now
+	}
+
+	if b.tokens >= 1 {
+		b.tokens -= 1
+		return true, 0, nil
+	}
+	return false, time.Duration(math.Ceil((1 - b.tokens) / l.rate)), nil
+}
+
+// remove buckets that are refilled already, these are identical to the newly created ones.
+func (l *localRateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.period {
+		return
+	}
+	l.lastSweep = now
+	for k, b := range l.buckets {
+		if now.Sub(b.updated) >= l.period {
+			delete(l.buckets, k)
+		}
+	}
+}
+
+// Create local token-bucket rate limiter, each key can make max number of requests in the period.
+//
+// The returned RouteRateLimiter can be used concurrently.
+func NewLocalRateLimiter(max int, period time.Duration) RouteRateLimiter {
+	return &localRateLimiter{
+		max:       float64(max),
+		period:    period,
+		rate:      float64(max) / float64(period),
+		buckets:   map[string]*tokenBucket{},
+		lastSweep: time.Now(),
+	}
+}
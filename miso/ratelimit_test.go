@@ -0,0 +1,87 @@
+package miso
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestLocalRateLimiter(t *testing.T) {
+	rail := EmptyRail()
+	rl := NewLocalRateLimiter(3, time.Second)
+	for i := range 3 {
+		ok, _, err := rl.AcquireKey(rail, "a")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			t.Fatalf("permit %v should be acquired", i)
+		}
+	}
+
+	ok, retryAfter, err := rl.AcquireKey(rail, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Fatal("permit should not be acquired")
+	}
+	if retryAfter <= 0 || retryAfter > time.Second/3+time.Millisecond {
+		t.Fatalf("unexpected retryAfter: %v", retryAfter)
+	}
+
+	// keys are isolated
+	ok, _, err = rl.AcquireKey(rail, "b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatal("permit for another key should be acquired")
+	}
+
+	time.Sleep(retryAfter)
+	ok, _, err = rl.AcquireKey(rail, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatal("permit should be acquired after refill")
+	}
+}
+
+func TestRouteRateLimit(t *testing.T) {
+	prev := lazyRouteRegistars
+	defer func() { lazyRouteRegistars = prev }()
+
+	gin.SetMode(gin.TestMode)
+	decl := HttpGet("/ratelimited", ResHandler(func(inb *Inbound) (string, error) { return "ok", nil })).
+		RateLimit(2, time.Minute, RateLimitByIp)
+
+	engine := gin.New()
+	engine.Handle(decl.Method, decl.Url, decl.Handler)
+
+	for i := range 3 {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/ratelimited", nil)
+		r.RemoteAddr = "10.0.0.1:1234"
+		r.Header.Set("X-Forwarded-For", fmt.Sprintf("192.168.0.%d", i)) // not trusted
+		engine.ServeHTTP(w, r)
+
+		if i < 2 {
+			if w.Code != http.StatusOK {
+				t.Fatalf("request %v, expected 200, got %v", i, w.Code)
+			}
+			continue
+		}
+		if w.Code != http.StatusTooManyRequests {
+			t.Fatalf("request %v, expected 429, got %v", i, w.Code)
+		}
+		if w.Header().Get("Retry-After") != "30" {
+			t.Fatalf("unexpected Retry-After: %v", w.Header().Get("Retry-After"))
+		}
+	}
+}
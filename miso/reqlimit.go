@@ -0,0 +1,137 @@
+package miso
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/curtisnewbie/miso/errs"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	ErrCodeRequestTimeout = "REQUEST_TIMEOUT"
+
+	ctxKeyRequestTimeout = "miso-RequestTimeout"
+)
+
+var (
+	ErrRequestTimeout = errs.NewErrfCode(ErrCodeRequestTimeout, "Request timeout, please try again later").
+		WithHttpStatus(http.StatusServiceUnavailable)
+)
+
+// Limit the time spent on handling the request, by default it's 'server.request.timeout'.
+//
+// The Rail of the handler is wrapped in a deadline, the deadline is propagated to [Client] and dbquery through the Rail context.
+// Once the deadline is exceeded, [ErrRequestTimeout] (503) is returned.
+//
+// The handler is not interrupted, it must respect the cancellation of the Rail context.
+func (g *LazyRouteDecl) Timeout(d time.Duration) *LazyRouteDecl {
+	if d < 1 {
+		panic(fmt.Errorf("invalid request timeout: %v", d))
+	}
+	g.timeout = d
+	return g
+}
+
+// Mark the route as a long-lived streaming route, e.g., SSE (see [SseHub.Subscribe]), 'server.request.timeout' is not applied
+// to the route, only the timeout specified using [LazyRouteDecl.Timeout] is applied.
+//
+// Routes declared using [HttpWs], and SSE or WebSocket upgrade requests (i.e., with 'Accept: text/event-stream' or
+// 'Upgrade: websocket' header) are always treated as streaming.
+func (g *LazyRouteDecl) Streaming() *LazyRouteDecl {
+	g.streaming = true
+	return g
+}
+
+// Limit the size of the request body in bytes, by default it's 'server.request.max-body-size'.
+//
+// Requests exceeding the limit are rejected with [ErrRequestEntityTooLarge] (413).
+//
+// Multipart requests are limited by [LazyRouteDecl.MaxUploadSize] instead.
+func (g *LazyRouteDecl) MaxBodySize(n int64) *LazyRouteDecl {
+	if n < 1 {
+		panic(fmt.Errorf("invalid max body size: %v", n))
+	}
+	g.maxBodySize = n
+	return g
+}
+
+// Build interceptor that enforces request timeout and body size limits, ok is false if there is no limit.
+func (g *LazyRouteDecl) requestLimiter() (func(c *gin.Context, next func()), bool) {
+	timeout := g.timeout
+	defTimeout := timeout < 1
+	if defTimeout {
+		if g.streaming {
+			timeout = 0
+		} else {
+			timeout = GetPropDuration(PropServerRequestTimeout)
+		}
+	}
+	maxBodySize := g.maxBodySize
+	if maxBodySize < 1 {
+		maxBodySize = int64(GetPropInt(PropServerRequestMaxBodySize))
+	}
+	maxUploadSize := g.maxUploadSize
+	if maxUploadSize < 1 {
+		maxUploadSize = int64(GetPropInt(PropServerMultipartMaxSize))
+	}
+	if timeout < 1 && maxBodySize < 1 && maxUploadSize < 1 {
+		return nil, false
+	}
+
+	return func(c *gin.Context, next func()) {
+		limit := maxBodySize
+		if c.ContentType() == gin.MIMEMultipartPOSTForm {
+			limit = maxUploadSize
+		}
+		if limit > 0 && c.Request.Body != nil {
+			if c.Request.ContentLength > limit {
+				handleEndpointResult(c, BuildRail(c), nil, ErrRequestEntityTooLarge.New())
+				return
+			}
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		}
+
+		if timeout < 1 || (defTimeout && isStreamingRequest(c.Request)) {
+			next()
+			return
+		}
+
+		ctx := c.Request.Context()
+		if GetPropBool(PropServerHandlerWithNewContext) {
+			ctx = context.WithoutCancel(ctx) // client disconnection doesn't cancel the handler, but the deadline does
+		}
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Set(ctxKeyRequestTimeout, true)
+
+		next()
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && !c.Writer.Written() {
+			handleEndpointResult(c, BuildRail(c), nil, ErrRequestTimeout.New())
+		}
+	}, true
+}
+
+// check whether the request is a long-lived SSE or WebSocket request.
+func isStreamingRequest(r *http.Request) bool {
+	return strings.Contains(strings.ToLower(r.Header.Get("Accept")), "text/event-stream") ||
+		strings.EqualFold(r.Header.Get("Upgrade"), "websocket")
+}
+
+// Translate errors caused by the request limits.
+func requestLimitErr(c *gin.Context, err error) error {
+	var mbe *http.MaxBytesError
+	if errors.As(err, &mbe) && !errs.IsAny(err, ErrRequestEntityTooLarge) {
+		return ErrRequestEntityTooLarge.Wrap(err)
+	}
+	if c.GetBool(ctxKeyRequestTimeout) && errors.Is(err, context.DeadlineExceeded) && !errs.IsAny(err, ErrRequestTimeout) {
+		return ErrRequestTimeout.Wrap(err)
+	}
+	return err
+}
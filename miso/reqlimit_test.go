@@ -0,0 +1,129 @@
+package miso
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequestLimits(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	SetProp(PropServerRequestMaxBodySize, 16)
+	defer SetProp(PropServerRequestMaxBodySize, 0)
+
+	type echoReq struct {
+		Name string `json:"name"`
+	}
+	var deadlineSet bool
+	engine := gin.New()
+	engine.Use(gin.RecoveryWithWriter(io.Discard, DefaultRecovery))
+	for _, decl := range []*LazyRouteDecl{
+		HttpGet("/slow", ResHandler(func(inb *Inbound) (any, error) {
+			rail := inb.Rail()
+			_, deadlineSet = rail.Context().Deadline()
+			<-rail.Done()
+			return nil, rail.Context().Err()
+		})).Timeout(50 * time.Millisecond),
+		HttpPost("/echo", AutoHandler(func(inb *Inbound, req echoReq) (string, error) {
+			return req.Name, nil
+		})),
+		HttpPost("/raw", RawHandler(func(inb *Inbound) {
+			_, r := inb.Unwrap()
+			_, err := io.ReadAll(r.Body)
+			inb.HandleResult(nil, err)
+		})).MaxBodySize(4),
+	} {
+		decl.prepare()
+		engine.Handle(decl.Method, decl.Url, decl.Handler)
+	}
+
+	w := httptest.NewRecorder()
+	start := time.Now()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	if w.Code != http.StatusServiceUnavailable || !deadlineSet || time.Since(start) > time.Second {
+		t.Fatalf("request should time out, %v, %v", w.Code, w.Body.String())
+	}
+
+	post := func(url string, body string, chunked bool) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, url, strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		if chunked {
+			r.ContentLength = -1
+		}
+		engine.ServeHTTP(w, r)
+		return w
+	}
+	if w := post("/echo", `{"name":"miso"}`, false); w.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %v, %v", w.Code, w.Body.String())
+	}
+	if w := post("/echo", `{"name":"miso-miso-miso"}`, false); w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("unexpected status: %v, %v", w.Code, w.Body.String())
+	}
+	if w := post("/echo", `{"name":"miso-miso-miso"}`, true); w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("unexpected status: %v, %v", w.Code, w.Body.String())
+	}
+	if w := post("/raw", `12345`, true); w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("unexpected status: %v, %v", w.Code, w.Body.String())
+	}
+}
+
+func TestRequestTimeoutStreaming(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	SetProp(PropServerRequestTimeout, "50ms")
+	SetProp(PropServerRequestMaxBodySize, 4)
+	defer func() {
+		SetProp(PropServerRequestTimeout, 0)
+		SetProp(PropServerRequestMaxBodySize, 0)
+	}()
+
+	deadlines := map[string]bool{}
+	handler := func(name string) httpHandler {
+		return RawHandler(func(inb *Inbound) {
+			_, deadlines[name] = inb.Rail().Context().Deadline()
+			inb.Status(http.StatusOK)
+		})
+	}
+	engine := gin.New()
+	for _, decl := range []*LazyRouteDecl{
+		HttpGet("/normal", handler("normal")),
+		HttpGet("/sse", handler("sse")),
+		HttpPost("/stream", handler("stream")).Streaming(),
+		HttpPost("/stream-timeout", handler("stream-timeout")).Streaming().Timeout(time.Second),
+	} {
+		decl.prepare()
+		engine.Handle(decl.Method, decl.Url, decl.Handler)
+	}
+
+	send := func(method string, url string, body string, header map[string]string) int {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(method, url, strings.NewReader(body))
+		for k, v := range header {
+			r.Header.Set(k, v)
+		}
+		engine.ServeHTTP(w, r)
+		return w.Code
+	}
+	send(http.MethodGet, "/normal", "", nil)
+	send(http.MethodGet, "/sse", "", map[string]string{"Accept": "text/event-stream"})
+	send(http.MethodPost, "/stream", "", nil)
+	send(http.MethodPost, "/stream-timeout", "", nil)
+	expected := map[string]bool{"normal": true, "sse": false, "stream": false, "stream-timeout": true}
+	for k, v := range expected {
+		if deadlines[k] != v {
+			t.Fatalf("%v, expected deadline: %v, got: %v", k, v, deadlines[k])
+		}
+	}
+
+	if code := send(http.MethodPost, "/stream", "12345", nil); code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("body size limit should apply to streaming routes, %v", code)
+	}
+	if !HttpWs("/ws", func(inb *Inbound, conn *WsConn[any, any]) error { return nil }).streaming {
+		t.Fatal("WebSocket route should be streaming")
+	}
+}
@@ -0,0 +1,252 @@
+package miso
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	HeaderXCache = "X-Cache"
+
+	ctxKeyEndpointErr = "miso-EndpointErr"
+)
+
+var (
+	responseCacheStore     ResponseCacheStore
+	responseCacheStoreOnce = sync.OnceValue(func() ResponseCacheStore {
+		if responseCacheStore != nil {
+			return responseCacheStore
+		}
+		return NewLocalResponseCacheStore(10_000)
+	})
+	responseCacheFlight singleflight.Group
+)
+
+// Cached response of GET endpoint.
+type CachedResponse struct {
+	ContentType string `json:"contentType"` // content-type of the response
+	Body        []byte `json:"body"`        // serialized response body
+}
+
+// Store of CachedResponse.
+type ResponseCacheStore interface {
+
+	// Get cached response, ok is false if the key is absent.
+	Get(rail Rail, key string) (r CachedResponse, ok bool, err error)
+
+	// Cache response for ttl.
+	Put(rail Rail, key string, r CachedResponse, ttl time.Duration) error
+
+	// Remove all cached responses with the key prefix.
+	DelPrefix(rail Rail, prefix string) error
+}
+
+// Replace the ResponseCacheStore used by endpoints declared with [LazyRouteDecl.Cache].
+//
+// By default, an in-process ResponseCacheStore backed by TTLCache is used, see [NewLocalResponseCacheStore].
+//
+// Must be called before the web server bootstraps.
+func SetResponseCacheStore(s ResponseCacheStore) {
+	if s == nil {
+		panic("ResponseCacheStore is nil")
+	}
+	responseCacheStore = s
+}
+
+// Func that builds the cache key of the request, see [LazyRouteDecl.Cache].
+type ResponseCacheKeyFunc func(inb *Inbound) string
+
+// Cache key varying by user, i.e., UserNo propagated by the gateway.
+func CacheKeyByUser() ResponseCacheKeyFunc {
+	return func(inb *Inbound) string {
+		return inb.Rail().User().UserNo
+	}
+}
+
+// Cache key varying by the query parameters, all query parameters are used if names is empty.
+func CacheKeyByQuery(names ...string) ResponseCacheKeyFunc {
+	return func(inb *Inbound) string {
+		_, r := inb.Unwrap()
+		q := r.URL.Query()
+		if len(names) < 1 {
+			return q.Encode() // sorted by key
+		}
+		v := url.Values{}
+		for _, n := range names {
+			if qv, ok := q[n]; ok {
+				v[n] = qv
+			}
+		}
+		return v.Encode()
+	}
+}
+
+// Cache key varying by the request headers.
+func CacheKeyByHeader(names ...string) ResponseCacheKeyFunc {
+	return func(inb *Inbound) string {
+		_, r := inb.Unwrap()
+		v := make([]string, 0, len(names))
+		for _, n := range names {
+			v = append(v, n+"="+r.Header.Get(n))
+		}
+		return strings.Join(v, "&")
+	}
+}
+
+// Cache key composed of the keys built by each ResponseCacheKeyFunc.
+func CacheKeyOf(fs ...ResponseCacheKeyFunc) ResponseCacheKeyFunc {
+	return func(inb *Inbound) string {
+		v := make([]string, 0, len(fs))
+		for _, f := range fs {
+			v = append(v, f(inb))
+		}
+		return strings.Join(v, "|")
+	}
+}
+
+// Cache successful responses of the GET endpoint for ttl.
+//
+// The cache key is the request path followed by the key built by keyFunc, e.g., '/open/api/stats:user=UE1' or
+// '/open/api/item/1:' for route '/open/api/item/:id'.
+// If keyFunc is nil, all query parameters are used, and the key also varies by user unless the route is public (see
+// [LazyRouteDecl.Public]), such that the cached responses are not shared among users,
+// see [CacheKeyByQuery], [CacheKeyByUser], [CacheKeyByHeader] and [CacheKeyOf].
+//
+// On cache miss, concurrent requests with the same key are coalesced, only one of them executes the handler,
+// the others wait for the response. Only responses with status 200 and without error are cached.
+//
+// Header 'X-Cache' is set to 'HIT' or 'MISS'.
+//
+// Use [InvalidateResponseCache] to remove cached responses.
+func (g *LazyRouteDecl) Cache(ttl time.Duration, keyFunc ResponseCacheKeyFunc) *LazyRouteDecl {
+	if g.Method != http.MethodGet {
+		panic(fmt.Errorf("response cache is only supported for GET endpoints, '%v %v'", g.Method, g.Url))
+	}
+	// scope may be declared after Cache(..), resolve the default key func lazily
+	getKeyFunc := sync.OnceValue(func() ResponseCacheKeyFunc {
+		if keyFunc != nil {
+			return keyFunc
+		}
+		for _, ex := range g.Extras {
+			if ex.Left == ExtraScope && ex.Right == ScopePublic {
+				return CacheKeyByQuery()
+			}
+		}
+		return CacheKeyOf(CacheKeyByUser(), CacheKeyByQuery())
+	})
+	return g.intercept(func(c *gin.Context, next func()) {
+		inb := newInbound(c)
+		rail := inb.Rail()
+		key := c.Request.URL.Path + ":" + getKeyFunc()(inb) // path params are part of the path
+		store := responseCacheStoreOnce()
+
+		cached, ok, err := store.Get(rail, key)
+		if err != nil {
+			rail.Warnf("Failed to get cached response, key: '%v', %v", key, err)
+		} else if ok {
+			writeCachedResponse(c, cached)
+			return
+		}
+
+		leader := false
+		v, _, _ := responseCacheFlight.Do(key, func() (any, error) {
+			leader = true
+			rw := &recordedResponseWriter{ResponseWriter: c.Writer}
+			c.Writer = rw
+			defer func() { c.Writer = rw.ResponseWriter }()
+
+			c.Header(HeaderXCache, "MISS")
+			next()
+
+			if rw.Status() != http.StatusOK || c.GetBool(ctxKeyEndpointErr) {
+				return nil, nil
+			}
+			r := CachedResponse{ContentType: rw.Header().Get("Content-Type"), Body: rw.buf.Bytes()}
+			if err := store.Put(rail, key, r, ttl); err != nil {
+				rail.Warnf("Failed to cache response, key: '%v', %v", key, err)
+			}
+			return r, nil
+		})
+		if leader {
+			return
+		}
+		if r, ok := v.(CachedResponse); ok {
+			writeCachedResponse(c, r)
+			return
+		}
+		next() // response of the coalesced request is not cacheable
+	})
+}
+
+func writeCachedResponse(c *gin.Context, r CachedResponse) {
+	if r.ContentType != "" {
+		c.Header("Content-Type", r.ContentType)
+	}
+	c.Header(HeaderXCache, "HIT")
+	c.Status(http.StatusOK)
+	if _, err := c.Writer.Write(r.Body); err != nil {
+		Errorf("Failed to write cached response, %v", err)
+	}
+}
+
+// Remove cached responses with the key prefix, e.g., '/open/api/stats' removes all cached responses of the route,
+// '/open/api/item/1:' removes the cached responses of item 1 for route '/open/api/item/:id'.
+func InvalidateResponseCache(rail Rail, prefix string) error {
+	return responseCacheStoreOnce().DelPrefix(rail, prefix)
+}
+
+type localResponseCacheStore struct {
+	mu      sync.Mutex
+	maxSize int
+	caches  map[time.Duration]TTLCache[CachedResponse]
+}
+
+func (s *localResponseCacheStore) Get(rail Rail, key string) (CachedResponse, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range s.caches {
+		if r, ok := c.TryGet(key); ok {
+			return r, true, nil
+		}
+	}
+	return CachedResponse{}, false, nil
+}
+
+func (s *localResponseCacheStore) Put(rail Rail, key string, r CachedResponse, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.caches[ttl]
+	if !ok {
+		c = NewTTLCache[CachedResponse](ttl, s.maxSize)
+		s.caches[ttl] = c
+	}
+	c.Put(key, r)
+	return nil
+}
+
+func (s *localResponseCacheStore) DelPrefix(rail Rail, prefix string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range s.caches {
+		for _, k := range c.Keys() {
+			if strings.HasPrefix(k, prefix) {
+				c.Del(k)
+			}
+		}
+	}
+	return nil
+}
+
+// Create in-process ResponseCacheStore backed by TTLCache.
+//
+// At most maxSize responses are kept for each ttl.
+func NewLocalResponseCacheStore(maxSize int) ResponseCacheStore {
+	return &localResponseCacheStore{maxSize: maxSize, caches: map[time.Duration]TTLCache[CachedResponse]{}}
+}
@@ -0,0 +1,123 @@
+package miso
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/curtisnewbie/miso/flow"
+	"github.com/gin-gonic/gin"
+)
+
+func TestResponseCache(t *testing.T) {
+	prevStore := responseCacheStoreOnce
+	defer func() { responseCacheStoreOnce = prevStore }()
+	store := NewLocalResponseCacheStore(100)
+	responseCacheStoreOnce = func() ResponseCacheStore { return store }
+
+	var cnt int32
+	block := make(chan struct{})
+	decl := HttpGet("/stats", ResHandler(func(inb *Inbound) (int32, error) {
+		if inb.Query("block") != "" {
+			<-block
+		}
+		if inb.Query("fail") != "" {
+			return 0, errors.New("fail")
+		}
+		return atomic.AddInt32(&cnt, 1), nil
+	})).Cache(time.Minute, CacheKeyOf(CacheKeyByUser(), CacheKeyByQuery()))
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(TraceMiddleware())
+	engine.Handle(decl.Method, decl.Url, decl.Handler)
+
+	send := func(url string, user string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, url, nil)
+		r.Header.Set(flow.XUserNo, user)
+		engine.ServeHTTP(w, r)
+		return w
+	}
+
+	w1 := send("/stats?a=1&b=2", "UE1")
+	w2 := send("/stats?b=2&a=1", "UE1")
+	if w1.Header().Get(HeaderXCache) != "MISS" || w2.Header().Get(HeaderXCache) != "HIT" || w1.Body.String() != w2.Body.String() {
+		t.Fatalf("response not cached, %v, %v", w1.Body.String(), w2.Body.String())
+	}
+	if w := send("/stats?a=1&b=2", "UE2"); w.Header().Get(HeaderXCache) != "MISS" {
+		t.Fatal("cache key should vary by user")
+	}
+	send("/stats?fail=1", "UE1")
+	if w := send("/stats?fail=1", "UE1"); w.Header().Get(HeaderXCache) != "MISS" {
+		t.Fatal("error response should not be cached")
+	}
+
+	if err := InvalidateResponseCache(EmptyRail(), "/stats"); err != nil {
+		t.Fatal(err)
+	}
+	if w := send("/stats?a=1&b=2", "UE1"); w.Header().Get(HeaderXCache) != "MISS" {
+		t.Fatal("response should be invalidated")
+	}
+
+	// cache key varies by path params
+	var itemCnt int32
+	itemDecl := HttpGet("/item/:id", ResHandler(func(inb *Inbound) (string, error) {
+		atomic.AddInt32(&itemCnt, 1)
+		return inb.Request().URL.Path, nil
+	})).Cache(time.Minute, nil)
+	engine.Handle(itemDecl.Method, itemDecl.Url, itemDecl.Handler)
+	i1 := send("/item/1", "UE1")
+	i2 := send("/item/2", "UE1")
+	if i2.Header().Get(HeaderXCache) != "MISS" || i1.Body.String() == i2.Body.String() {
+		t.Fatalf("cache key should vary by path params, %v, %v", i1.Body.String(), i2.Body.String())
+	}
+	if w := send("/item/1", "UE1"); w.Header().Get(HeaderXCache) != "HIT" || w.Body.String() != i1.Body.String() {
+		t.Fatalf("response not cached, %v", w.Body.String())
+	}
+	if err := InvalidateResponseCache(EmptyRail(), "/item/1:"); err != nil {
+		t.Fatal(err)
+	}
+	if w := send("/item/1", "UE1"); w.Header().Get(HeaderXCache) != "MISS" {
+		t.Fatal("response should be invalidated")
+	}
+	if w := send("/item/2", "UE1"); w.Header().Get(HeaderXCache) != "HIT" {
+		t.Fatal("response of other path params should not be invalidated")
+	}
+
+	// default cache key varies by user unless the route is public
+	if w := send("/item/2", "UE2"); w.Header().Get(HeaderXCache) != "MISS" {
+		t.Fatal("cache key should vary by user by default")
+	}
+	pubDecl := HttpGet("/pub", ResHandler(func(inb *Inbound) (string, error) { return "pub", nil })).
+		Cache(time.Minute, nil).
+		Public()
+	engine.Handle(pubDecl.Method, pubDecl.Url, pubDecl.Handler)
+	send("/pub", "UE1")
+	if w := send("/pub", "UE2"); w.Header().Get(HeaderXCache) != "HIT" {
+		t.Fatal("response of public route should be shared among users")
+	}
+
+	// concurrent misses are coalesced
+	before := atomic.LoadInt32(&cnt)
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if w := send("/stats?block=1", "UE1"); w.Code != http.StatusOK {
+				t.Errorf("unexpected status, %v", w.Code)
+			}
+		}()
+	}
+	time.Sleep(100 * time.Millisecond)
+	close(block)
+	wg.Wait()
+	if n := atomic.LoadInt32(&cnt) - before; n != 1 {
+		t.Fatalf("handler should be executed once, executed %v times", n)
+	}
+}
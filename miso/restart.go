@@ -0,0 +1,137 @@
+package miso
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Env names are deliberately not prefixed with 'MISO_', otherwise they are loaded as config props.
+const (
+	// listeners inherited from the previous process, e.g., '127.0.0.1:8080=3,127.0.0.1:8081=4'.
+	envRestartListeners = "MISORESTART_LISTENERS"
+
+	// fd of the pipe used to notify the previous process that the new process is ready.
+	envRestartReadyFd = "MISORESTART_READY_FD"
+
+	restartApiUrl = "/debug/restart"
+)
+
+var (
+	// tcp listeners that can be handed off to the new process, keyed by the address.
+	restartListeners   = map[string]*net.TCPListener{}
+	restartListenersMu sync.Mutex
+
+	// listener fds inherited from the previous process, keyed by the address.
+	inheritedListenerFds = sync.OnceValue(func() map[string]int {
+		v := os.Getenv(envRestartListeners)
+		os.Unsetenv(envRestartListeners)
+		return parseRestartListeners(v)
+	})
+
+	gracefulRestarting = &atomic.Bool{}
+)
+
+// Whether current process is handing off the listeners to the new process, see 'server.graceful-restart.enabled'.
+//
+// When it's true, the new process has already registered itself on service discovery (e.g., Consul and Nacos)
+// using the same address, the current process should not deregister it.
+func IsGracefulRestarting() bool {
+	return gracefulRestarting.Load()
+}
+
+func parseRestartListeners(v string) map[string]int {
+	fds := map[string]int{}
+	for _, s := range strings.Split(v, ",") {
+		i := strings.LastIndex(s, "=")
+		if i < 0 {
+			continue
+		}
+		fd, err := strconv.Atoi(s[i+1:])
+		if err != nil {
+			continue
+		}
+		fds[s[:i]] = fd
+	}
+	return fds
+}
+
+// Listen on the address, the listener is inherited from the previous process if it's restarted gracefully.
+func listenTcp(rail Rail, addr string) (net.Listener, error) {
+	var ln net.Listener
+	if fd, ok := inheritedListenerFds()[addr]; ok {
+		f := os.NewFile(uintptr(fd), addr)
+		l, err := net.FileListener(f)
+		f.Close() // FileListener dups the fd
+		if err != nil {
+			return nil, fmt.Errorf("failed to inherit listener for '%v' (fd: %v), %w", addr, fd, err)
+		}
+		rail.Infof("Inherited listener for '%v' from previous process", addr)
+		ln = l
+	} else {
+		l, err := net.Listen("tcp", addr)
+		if err != nil {
+			return nil, err
+		}
+		ln = l
+	}
+
+	if tl, ok := ln.(*net.TCPListener); ok {
+		restartListenersMu.Lock()
+		restartListeners[addr] = tl
+		restartListenersMu.Unlock()
+	}
+	return ln, nil
+}
+
+// Notify the previous process that current process is ready, the previous process then shuts down gracefully.
+func notifyRestartReady(rail Rail) {
+	v := os.Getenv(envRestartReadyFd)
+	if v == "" {
+		return
+	}
+	os.Unsetenv(envRestartReadyFd)
+	fd, err := strconv.Atoi(v)
+	if err != nil {
+		rail.Errorf("Invalid %v: '%v'", envRestartReadyFd, v)
+		return
+	}
+	f := os.NewFile(uintptr(fd), "restart-ready")
+	defer f.Close()
+	if _, err := f.Write([]byte("ready")); err != nil {
+		rail.Errorf("Failed to notify previous process, %v", err)
+		return
+	}
+	rail.Info("Notified previous process that current process is ready")
+}
+
+func prepGracefulRestart(rail Rail) {
+	if !GetPropBool(PropServerGracefulRestartEnabled) {
+		return
+	}
+	OnAppReady(func(rail Rail) error {
+		return watchRestartSignal(rail)
+	})
+
+	if !IsAdminServerEnabled() {
+		rail.Infof("Admin http server is disabled, '%v' is not registered, use SIGHUP to trigger graceful restart", restartApiUrl)
+		return
+	}
+	HttpPost(restartApiUrl, RawHandler(func(inb *Inbound) {
+		rail := inb.Rail()
+		go func() {
+			if err := GracefulRestart(rail.NewCtx()); err != nil {
+				rail.Errorf("Graceful restart failed, %v", err)
+			}
+		}()
+		inb.Status(http.StatusAccepted)
+	})).
+		Desc("Trigger graceful restart, the new process inherits the listeners, and current process shuts down once the new process is ready.").
+		Admin()
+	rail.Infof("Registered 'POST %v' on admin http server for graceful restart", restartApiUrl)
+}
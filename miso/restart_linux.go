@@ -0,0 +1,138 @@
+package miso
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+var restartMu sync.Mutex
+
+// Restart current process gracefully without downtime, it's only supported on Linux.
+//
+// A new process is started using the same executable and arguments, the new process inherits the listeners of the http servers.
+// Once the new process is ready, current process shuts down gracefully, i.e., the ordered shutdown hooks are triggered,
+// and the in-flight requests are handled within 'server.graceful-shutdown-time-sec'.
+//
+// If the new process fails to start or it's not ready within 'server.graceful-restart.ready-timeout',
+// the new process is killed and current process keeps serving.
+func GracefulRestart(rail Rail) error {
+	if !restartMu.TryLock() {
+		return errors.New("graceful restart is already in progress")
+	}
+	defer restartMu.Unlock()
+	if IsShuttingDown() {
+		return errors.New("server is shutting down")
+	}
+
+	restartListenersMu.Lock()
+	addrs := make([]string, 0, len(restartListeners))
+	for addr := range restartListeners {
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+	files := make([]*os.File, 0, len(addrs)+1)
+	fds := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		f, err := restartListeners[addr].File()
+		if err != nil {
+			restartListenersMu.Unlock()
+			closeFiles(files)
+			return fmt.Errorf("failed to get listener fd of '%v', %w", addr, err)
+		}
+		fds = append(fds, fmt.Sprintf("%s=%d", addr, 3+len(files))) // ExtraFiles starts at fd 3
+		files = append(files, f)
+	}
+	restartListenersMu.Unlock()
+	defer closeFiles(files)
+
+	ready, readyW, err := os.Pipe()
+	if err != nil {
+		return fmt.Errorf("failed to create pipe, %w", err)
+	}
+	defer ready.Close()
+
+	exe, err := os.Executable()
+	if err != nil {
+		readyW.Close()
+		return fmt.Errorf("failed to resolve executable, %w", err)
+	}
+	cmd := exec.Command(exe, os.Args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.ExtraFiles = append(files, readyW)
+	cmd.Env = append(os.Environ(),
+		envRestartListeners+"="+strings.Join(fds, ","),
+		fmt.Sprintf("%s=%d", envRestartReadyFd, 3+len(files)),
+	)
+	err = cmd.Start()
+	readyW.Close() // only the new process holds the write end
+	if err != nil {
+		return fmt.Errorf("failed to start new process, %w", err)
+	}
+	rail.Infof("Started new process (pid: %v), waiting for it to be ready", cmd.Process.Pid)
+
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+	readied := make(chan error, 1)
+	go func() {
+		buf := make([]byte, 5)
+		_, err := io.ReadFull(ready, buf)
+		readied <- err
+	}()
+
+	timeout := GetPropDuration(PropServerGracefulRestartReadyTimeout)
+	select {
+	case err := <-readied:
+		if err != nil {
+			cmd.Process.Kill()
+			return fmt.Errorf("new process (pid: %v) exited before it's ready, %w", cmd.Process.Pid, err)
+		}
+	case err := <-exited:
+		return fmt.Errorf("new process (pid: %v) exited before it's ready, %v", cmd.Process.Pid, err)
+	case <-time.After(timeout):
+		cmd.Process.Kill()
+		return fmt.Errorf("new process (pid: %v) is not ready within %v", cmd.Process.Pid, timeout)
+	}
+
+	rail.Infof("New process (pid: %v) is ready, shutting down current process", cmd.Process.Pid)
+	gracefulRestarting.Store(true)
+	Shutdown()
+	return nil
+}
+
+func closeFiles(files []*os.File) {
+	for _, f := range files {
+		f.Close()
+	}
+}
+
+func watchRestartSignal(rail Rail) error {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGHUP)
+	go func() {
+		for {
+			select {
+			case <-c:
+				rail.Infof("Received SIGHUP, restarting gracefully")
+				if err := GracefulRestart(rail); err != nil {
+					rail.Errorf("Graceful restart failed, %v", err)
+				}
+			case <-IsShuttingDownCh():
+				signal.Stop(c)
+				return
+			}
+		}
+	}()
+	rail.Infof("Listening to SIGHUP for graceful restart")
+	return nil
+}
@@ -0,0 +1,72 @@
+package miso
+
+import (
+	"fmt"
+	"net"
+	"syscall"
+	"testing"
+)
+
+func TestParseRestartListeners(t *testing.T) {
+	fds := parseRestartListeners("127.0.0.1:8080=3,:8081=4,invalid,[::1]:8082=x")
+	if len(fds) != 2 || fds["127.0.0.1:8080"] != 3 || fds[":8081"] != 4 {
+		t.Fatalf("unexpected fds: %v", fds)
+	}
+}
+
+func TestInheritListener(t *testing.T) {
+	prev := inheritedListenerFds
+	defer func() { inheritedListenerFds = prev }()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	f, err := ln.(*net.TCPListener).File()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fd, err := syscall.Dup(int(f.Fd())) // fd owned by the inherited listener
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const addr = "inherited:0"
+	inheritedListenerFds = func() map[string]int { return parseRestartListeners(fmt.Sprintf("%v=%v", addr, fd)) }
+	il, err := listenTcp(EmptyRail(), addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		restartListenersMu.Lock()
+		delete(restartListeners, addr)
+		restartListenersMu.Unlock()
+		il.Close()
+	}()
+	if il.Addr().String() != ln.Addr().String() {
+		t.Fatalf("listener not inherited, %v, %v", il.Addr(), ln.Addr())
+	}
+
+	// connections to the original address are accepted by the inherited listener
+	ln.Close()
+	go func() {
+		c, err := net.Dial("tcp", il.Addr().String())
+		if err == nil {
+			c.Close()
+		}
+	}()
+	c, err := il.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Close()
+
+	restartListenersMu.Lock()
+	_, ok := restartListeners[addr]
+	restartListenersMu.Unlock()
+	if !ok {
+		t.Fatal("inherited listener should be handed off on next restart")
+	}
+}
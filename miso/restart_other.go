@@ -0,0 +1,15 @@
+//go:build !linux
+
+package miso
+
+import "errors"
+
+// Restart current process gracefully without downtime, it's only supported on Linux.
+func GracefulRestart(rail Rail) error {
+	return errors.New("graceful restart is only supported on linux")
+}
+
+func watchRestartSignal(rail Rail) error {
+	rail.Warnf("Graceful restart is only supported on linux, SIGHUP is ignored")
+	return nil
+}
@@ -340,5 +340,5 @@ func registerRouteForJobTriggers() {
 			rail.Infof("Triggered job %v through api", name)
 		}
 		inb.HandleResult(nil, err)
-	})).DocQueryParam("name", "job name").Desc("Manually Trigger Cron Job By Name")
+	})).DocQueryParam("name", "job name").Desc("Manually Trigger Cron Job By Name").Admin()
 }
@@ -12,6 +12,8 @@ var (
 )
 
 // Perf Middleware that calculates how much time each request takes
+//
+// Deprecated: use structured access log instead, see 'server.access-log.*' props.
 func PerfMiddleware() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		uri := ctx.Request.RequestURI
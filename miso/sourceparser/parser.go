@@ -42,7 +42,9 @@ type ParsedEndpoint struct {
 	FuncName      string   // from Extra(miso.ExtraName, ...) if present
 	RequestRef    *TypeRef // request type
 	ResponseRef   *TypeRef // response type
+	RateLimit     string   // from RateLimit(max, period, keyFunc) or RateLimitWith(limiter, keyFunc)
 	NoDoc         bool     // true if .NoDoc() was called
+	WebSocket     bool     // true if registered using HttpWs, RequestRef and ResponseRef are the websocket message types
 	File          string   // source file path (set by ParseFile)
 }
 
@@ -345,11 +347,14 @@ func collectPipelineChain(pp *ParsedPipeline, name string, args []dst.Expr) {
 // analyzeCallChain recursively descends through chained method calls
 // to find the root miso.Http* call and collect all chained methods.
 func analyzeCallChain(call *dst.CallExpr, constVars map[string]string, pkg *types.Package, filePkgName string, fileFuncs map[string]*dst.FuncType) *ParsedEndpoint {
-	sel, ok := call.Fun.(*dst.SelectorExpr)
+	sel, ok := unwrapTypeArgs(call.Fun).(*dst.SelectorExpr)
 	if !ok {
 		// Bare ident (e.g., HttpGet in package miso) — only allowed when file is package miso
 		if filePkgName == "miso" {
-			if ident, ok := call.Fun.(*dst.Ident); ok {
+			if ident, ok := unwrapTypeArgs(call.Fun).(*dst.Ident); ok {
+				if ident.Name == "HttpWs" {
+					return newWsEndpoint(call, constVars, pkg, fileFuncs)
+				}
 				method, ok := httpMethodMap[ident.Name]
 				if !ok {
 					return nil
@@ -379,6 +384,9 @@ func analyzeCallChain(call *dst.CallExpr, constVars map[string]string, pkg *type
 
 	// Base case: miso.Http*(...)
 	if ident, ok := sel.X.(*dst.Ident); ok && ident.Name == "miso" {
+		if sel.Sel.Name == "HttpWs" {
+			return newWsEndpoint(call, constVars, pkg, fileFuncs)
+		}
 		method, ok := httpMethodMap[sel.Sel.Name]
 		if !ok {
 			return nil
@@ -398,6 +406,90 @@ func analyzeCallChain(call *dst.CallExpr, constVars map[string]string, pkg *type
 	return nil
 }
 
+// unwrapTypeArgs strips explicit type arguments, e.g., miso.HttpWs[Req, Res] to miso.HttpWs.
+func unwrapTypeArgs(fun dst.Expr) dst.Expr {
+	switch v := fun.(type) {
+	case *dst.IndexExpr:
+		return v.X
+	case *dst.IndexListExpr:
+		return v.X
+	}
+	return fun
+}
+
+// newWsEndpoint builds ParsedEndpoint for miso.HttpWs(url, handler).
+func newWsEndpoint(call *dst.CallExpr, constVars map[string]string, pkg *types.Package, fileFuncs map[string]*dst.FuncType) *ParsedEndpoint {
+	ep := &ParsedEndpoint{Method: "GET", Handler: "HttpWs", WebSocket: true}
+	if len(call.Args) > 0 {
+		ep.URL = wrapUnresolvedURLIdent(call.Args, 0, extractStringArg(call.Args, 0, constVars, pkg))
+	}
+	if len(call.Args) < 2 {
+		return ep
+	}
+	switch v := call.Args[1].(type) {
+	case *dst.FuncLit:
+		extractWsFuncType(v.Type, ep)
+	case *dst.Ident:
+		if ft, ok := fileFuncs[v.Name]; ok {
+			extractWsFuncType(ft, ep)
+		} else if pkg != nil {
+			if obj := pkg.Scope().Lookup(v.Name); obj != nil {
+				if fn, ok := obj.(*types.Func); ok {
+					extractWsSignature(fn.Type().(*types.Signature), ep)
+				}
+			}
+		}
+	}
+	return ep
+}
+
+// extractWsFuncType pulls the message types from the *miso.WsConn[Req, Res] parameter.
+func extractWsFuncType(ft *dst.FuncType, ep *ParsedEndpoint) {
+	if ft.Params == nil {
+		return
+	}
+	for _, field := range ft.Params.List {
+		star, ok := field.Type.(*dst.StarExpr)
+		if !ok {
+			continue
+		}
+		il, ok := star.X.(*dst.IndexListExpr)
+		if !ok || len(il.Indices) != 2 {
+			continue
+		}
+		name := ""
+		switch x := il.X.(type) {
+		case *dst.SelectorExpr:
+			name = x.Sel.Name
+		case *dst.Ident:
+			name = x.Name
+		}
+		if name != "WsConn" {
+			continue
+		}
+		req, res := exprToTypeRef(il.Indices[0]), exprToTypeRef(il.Indices[1])
+		ep.RequestRef, ep.ResponseRef = &req, &res
+		return
+	}
+}
+
+// extractWsSignature pulls the message types from the *miso.WsConn[Req, Res] parameter using go/types.
+func extractWsSignature(sig *types.Signature, ep *ParsedEndpoint) {
+	for i := 0; i < sig.Params().Len(); i++ {
+		ptr, ok := sig.Params().At(i).Type().(*types.Pointer)
+		if !ok {
+			continue
+		}
+		named, ok := ptr.Elem().(*types.Named)
+		if !ok || named.Obj().Name() != "WsConn" || named.TypeArgs().Len() != 2 {
+			continue
+		}
+		req, res := typeToTypeRef(named.TypeArgs().At(0)), typeToTypeRef(named.TypeArgs().At(1))
+		ep.RequestRef, ep.ResponseRef = &req, &res
+		return
+	}
+}
+
 // extractFuncName extracts the FuncName from Extra("miso.ExtraName", ...) if present.
 func extractFuncName(ep *ParsedEndpoint) {
 	for _, extra := range ep.Extras {
@@ -538,6 +630,20 @@ func collectChained(ep *ParsedEndpoint, name string, args []dst.Expr, constVars
 		if ep.HeaderReqType == "" && len(args) > 0 {
 			ep.HeaderReqType = extractTypeFromExpr(args[0])
 		}
+	case "RateLimit":
+		if len(args) >= 2 {
+			ep.RateLimit = fmt.Sprintf("%s requests per %s", exprToSource(args[0]), exprToSource(args[1]))
+			if len(args) >= 3 {
+				ep.RateLimit += ", by " + rateLimitKeyDesc(args[2])
+			}
+		}
+	case "RateLimitWith":
+		ep.RateLimit = "custom"
+		if len(args) >= 2 {
+			ep.RateLimit += ", by " + rateLimitKeyDesc(args[1])
+		}
+	case "Idempotent":
+		ep.Headers = append(ep.Headers, pair.StrPair{Left: "Idempotency-Key", Right: "Optional idempotency key, responses are replayed for retries with the same key."})
 	case "NoDoc":
 		ep.NoDoc = true
 	case "Extra":
@@ -986,6 +1092,53 @@ func exprToValue(e dst.Expr) string {
 	}
 }
 
+// rateLimitKeyDesc describes the RateLimitKeyFunc argument of RateLimit(...) or RateLimitWith(...).
+func rateLimitKeyDesc(e dst.Expr) string {
+	switch v := e.(type) {
+	case *dst.Ident:
+		switch v.Name {
+		case "nil", "RateLimitByIp":
+			return "client ip"
+		case "RateLimitByUser":
+			return "user"
+		}
+	case *dst.SelectorExpr:
+		switch v.Sel.Name {
+		case "RateLimitByIp":
+			return "client ip"
+		case "RateLimitByUser":
+			return "user"
+		}
+	case *dst.FuncLit:
+		return "custom key"
+	}
+	return exprToSource(e)
+}
+
+// exprToSource converts a value expression (dst.Expr) to a source-like string representation.
+// Handles: *dst.BasicLit, *dst.BinaryExpr, *dst.ParenExpr, *dst.UnaryExpr, *dst.CallExpr,
+// and falls back to exprToString for the others.
+func exprToSource(e dst.Expr) string {
+	switch v := e.(type) {
+	case *dst.BasicLit:
+		return v.Value
+	case *dst.BinaryExpr:
+		return exprToSource(v.X) + " " + v.Op.String() + " " + exprToSource(v.Y)
+	case *dst.ParenExpr:
+		return "(" + exprToSource(v.X) + ")"
+	case *dst.UnaryExpr:
+		return v.Op.String() + exprToSource(v.X)
+	case *dst.CallExpr:
+		args := make([]string, 0, len(v.Args))
+		for _, a := range v.Args {
+			args = append(args, exprToSource(a))
+		}
+		return exprToString(v.Fun) + "(" + strings.Join(args, ", ") + ")"
+	default:
+		return exprToString(e)
+	}
+}
+
 // exprToString converts a type expression (dst.Expr) to its string representation.
 // Handles: *dst.Ident, *dst.StarExpr, *dst.SelectorExpr, *dst.ArrayType,
 // *dst.IndexExpr (generics), *dst.MapType.
@@ -1983,3 +1983,34 @@ func init() {
 		t.Errorf("URL=%q, want %q (variable ident gets /${} wrapping consistent with TestParseFile_VariableURL)", eps[0].URL, "/${baseURL}/health")
 	}
 }
+
+func TestParseFile_RateLimit(t *testing.T) {
+	ep := parseSingle(t, `package test
+import "github.com/curtisnewbie/miso/miso"
+func init() {
+	miso.HttpPost("/api/v1", miso.AutoHandler(
+		func(inb *miso.Inbound, req Req) (Res, error) { return nil, nil },
+	)).RateLimit(100, time.Second*10, miso.RateLimitByUser)
+}`)
+	if want := "100 requests per time.Second * 10, by user"; ep.RateLimit != want {
+		t.Errorf("RateLimit = %q, want %q", ep.RateLimit, want)
+	}
+}
+
+func TestParseFile_HttpWs(t *testing.T) {
+	ep := parseSingle(t, `package test
+import "github.com/curtisnewbie/miso/miso"
+func init() {
+	miso.HttpWs("/api/ws", func(inb *miso.Inbound, conn *miso.WsConn[ChatReq, *ChatRes]) error { return nil }).
+		Desc("chat")
+}`)
+	if !ep.WebSocket || ep.Method != "GET" || ep.URL != "/api/ws" || ep.Desc != "chat" {
+		t.Fatalf("unexpected endpoint: %+v", ep)
+	}
+	if ep.RequestRef == nil || ep.RequestRef.Name != "ChatReq" {
+		t.Errorf("RequestRef = %+v, want ChatReq", ep.RequestRef)
+	}
+	if ep.ResponseRef == nil || ep.ResponseRef.Name != "ChatRes" || !ep.ResponseRef.IsPtr {
+		t.Errorf("ResponseRef = %+v, want *ChatRes", ep.ResponseRef)
+	}
+}
@@ -0,0 +1,283 @@
+package miso
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/curtisnewbie/miso/errs"
+	"github.com/curtisnewbie/miso/util/idutil"
+	"github.com/curtisnewbie/miso/util/json"
+)
+
+const (
+	HeaderLastEventId = "Last-Event-ID"
+)
+
+// Event broadcast by SseHub.
+type SseEvent struct {
+	Id    string `json:"id"`    // event id, clients resume from the id using Last-Event-ID header
+	Event string `json:"event"` // event name, optional
+	Data  string `json:"data"`  // serialized event data
+}
+
+// Fan-out of SseHub events among app instances.
+type SseFanout interface {
+
+	// Publish event to all instances, including the current one.
+	Publish(rail Rail, channel string, e SseEvent) error
+
+	// Subscribe events published by all instances.
+	Subscribe(rail Rail, deliver func(rail Rail, channel string, e SseEvent)) error
+}
+
+type SseHubConfig struct {
+	ReplaySize       int           // max number of events kept for each channel for Last-Event-ID resume, 100 by default.
+	Heartbeat        time.Duration // interval of heartbeat comments, 15s by default.
+	SubscriberBuffer int           // max number of events buffered for each subscriber, slow subscribers are disconnected, 64 by default.
+	IdleTimeout      time.Duration // channels without subscribers are removed (with the kept events) after being idle for the duration, 5m by default.
+}
+
+// Server-side hub of SSE channels.
+//
+// Handlers subscribe to named channels using [SseHub.Subscribe], events are broadcast to all subscribers
+// of the channel using [SseHub.Broadcast] from anywhere.
+//
+// Recent events are kept for each channel, reconnecting clients resume from the Last-Event-ID header.
+//
+// By default, events are only broadcast to the clients connected to the current instance,
+// use [SseHub.SetFanout] to broadcast events among all instances, e.g., using redis.NewSseFanout.
+type SseHub struct {
+	conf     SseHubConfig
+	mu       sync.Mutex
+	channels map[string]*sseChannel
+	fanout   SseFanout
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+type sseChannel struct {
+	subs       map[*sseSubscriber]struct{}
+	replay     []SseEvent
+	lastActive time.Time
+}
+
+type sseSubscriber struct {
+	events chan SseEvent
+	slow   chan struct{}
+}
+
+// Create SseHub.
+//
+// Subscribers are disconnected when the app shuts down.
+func NewSseHub(options ...func(c *SseHubConfig)) *SseHub {
+	c := SseHubConfig{ReplaySize: 100, Heartbeat: 15 * time.Second, SubscriberBuffer: 64, IdleTimeout: 5 * time.Minute}
+	for _, op := range options {
+		op(&c)
+	}
+	h := &SseHub{
+		conf:     c,
+		channels: map[string]*sseChannel{},
+		closed:   make(chan struct{}),
+	}
+	AddShutdownHook(h.Close)
+	if c.IdleTimeout > 0 {
+		go h.evictIdleChannels()
+	}
+	return h
+}
+
+// remove idle channels periodically until the hub is closed.
+func (h *SseHub) evictIdleChannels() {
+	t := time.NewTicker(max(h.conf.IdleTimeout/2, time.Millisecond))
+	defer t.Stop()
+	for {
+		select {
+		case <-h.closed:
+			return
+		case now := <-t.C:
+			h.mu.Lock()
+			for name, ch := range h.channels {
+				if len(ch.subs) < 1 && now.Sub(ch.lastActive) >= h.conf.IdleTimeout {
+					delete(h.channels, name)
+				}
+			}
+			h.mu.Unlock()
+		}
+	}
+}
+
+// Broadcast events among all instances using SseFanout.
+//
+// Must be called before the server bootstraps, the hub subscribes to the SseFanout once the server is bootstrapped.
+func (h *SseHub) SetFanout(f SseFanout) {
+	h.fanout = f
+	PostServerBootstrap(func(rail Rail) error {
+		return f.Subscribe(rail, func(rail Rail, channel string, e SseEvent) {
+			h.deliver(channel, e)
+		})
+	})
+}
+
+// Broadcast event to all subscribers of the channel, data is serialized as json unless it's a string.
+func (h *SseHub) Broadcast(rail Rail, channel string, event string, data any) error {
+	e := SseEvent{Id: idutil.New(), Event: event}
+	if s, ok := data.(string); ok {
+		e.Data = s
+	} else {
+		s, err := json.SWriteJson(data)
+		if err != nil {
+			return errs.Wrapf(err, "failed to serialize sse event data")
+		}
+		e.Data = s
+	}
+	if h.fanout != nil {
+		return h.fanout.Publish(rail, channel, e)
+	}
+	h.deliver(channel, e)
+	return nil
+}
+
+// Subscribe to the channel, events are written to the response until the client disconnects or the app shuts down.
+//
+// Events after Last-Event-ID are replayed first. If Last-Event-ID is not found (e.g., it's too old), all the kept events are replayed.
+func (h *SseHub) Subscribe(inb *Inbound, channel string) error {
+	rail := inb.Rail()
+	w, r := inb.Unwrap()
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return errs.NewErrf("streaming is not supported")
+	}
+
+	sub := &sseSubscriber{events: make(chan SseEvent, h.conf.SubscriberBuffer), slow: make(chan struct{})}
+	replay := h.subscribe(channel, sub, r.Header.Get(HeaderLastEventId))
+	defer h.unsubscribe(channel, sub)
+
+	hd := w.Header()
+	hd.Set("Content-Type", "text/event-stream")
+	hd.Set("Cache-Control", "no-cache")
+	hd.Set("Connection", "keep-alive")
+	hd.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	for _, e := range replay {
+		if err := writeSseEvent(w, e); err != nil {
+			return nil
+		}
+	}
+	flusher.Flush()
+
+	var heartbeat <-chan time.Time
+	if h.conf.Heartbeat > 0 {
+		t := time.NewTicker(h.conf.Heartbeat)
+		defer t.Stop()
+		heartbeat = t.C
+	}
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return nil
+		case <-h.closed:
+			return nil
+		case <-sub.slow:
+			rail.Warnf("SSE subscriber of channel '%v' is too slow, disconnecting", channel)
+			return nil
+		case <-heartbeat:
+			if _, err := w.Write([]byte(": heartbeat\n\n")); err != nil {
+				return nil
+			}
+			flusher.Flush()
+		case e := <-sub.events:
+			if err := writeSseEvent(w, e); err != nil {
+				return nil
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// Disconnect all subscribers.
+func (h *SseHub) Close() {
+	h.closeOnce.Do(func() { close(h.closed) })
+}
+
+// register subscriber and return the events to replay.
+func (h *SseHub) subscribe(channel string, sub *sseSubscriber, lastEventId string) []SseEvent {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	ch := h.channel(channel)
+	ch.subs[sub] = struct{}{}
+	if lastEventId == "" {
+		return nil
+	}
+	for i, e := range ch.replay {
+		if e.Id == lastEventId {
+			return append([]SseEvent(nil), ch.replay[i+1:]...)
+		}
+	}
+	return append([]SseEvent(nil), ch.replay...)
+}
+
+func (h *SseHub) unsubscribe(channel string, sub *sseSubscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if ch, ok := h.channels[channel]; ok {
+		delete(ch.subs, sub)
+		ch.lastActive = time.Now()
+		if len(ch.subs) < 1 && len(ch.replay) < 1 {
+			delete(h.channels, channel)
+		}
+	}
+}
+
+func (h *SseHub) channel(channel string) *sseChannel {
+	ch, ok := h.channels[channel]
+	if !ok {
+		ch = &sseChannel{subs: map[*sseSubscriber]struct{}{}}
+		h.channels[channel] = ch
+	}
+	return ch
+}
+
+// deliver event to local subscribers.
+func (h *SseHub) deliver(channel string, e SseEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	ch := h.channel(channel)
+	ch.lastActive = time.Now()
+	if h.conf.ReplaySize > 0 {
+		if len(ch.replay) >= h.conf.ReplaySize {
+			ch.replay = append(ch.replay[:0], ch.replay[len(ch.replay)-h.conf.ReplaySize+1:]...)
+		}
+		ch.replay = append(ch.replay, e)
+	}
+	for sub := range ch.subs {
+		select {
+		case sub.events <- e:
+		default:
+			// subscriber is too slow, it can resume using Last-Event-ID
+			delete(ch.subs, sub)
+			close(sub.slow)
+		}
+	}
+}
+
+func writeSseEvent(w http.ResponseWriter, e SseEvent) error {
+	var b strings.Builder
+	if e.Id != "" {
+		fmt.Fprintf(&b, "id: %s\n", e.Id)
+	}
+	if e.Event != "" {
+		fmt.Fprintf(&b, "event: %s\n", e.Event)
+	}
+	for _, l := range strings.Split(e.Data, "\n") {
+		fmt.Fprintf(&b, "data: %s\n", l)
+	}
+	b.WriteString("\n")
+	_, err := w.Write([]byte(b.String()))
+	return err
+}
@@ -0,0 +1,156 @@
+package miso
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSseHubReplay(t *testing.T) {
+	hub := NewSseHub(func(c *SseHubConfig) { c.ReplaySize = 3 })
+	defer hub.Close()
+	rail := EmptyRail()
+	for i := 0; i < 5; i++ {
+		if err := hub.Broadcast(rail, "news", "msg", i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	replay := hub.channels["news"].replay
+	if len(replay) != 3 || replay[0].Data != "2" || replay[2].Data != "4" {
+		t.Fatalf("unexpected replay buffer: %+v", replay)
+	}
+
+	sub := &sseSubscriber{events: make(chan SseEvent, 1), slow: make(chan struct{})}
+	if v := hub.subscribe("news", sub, replay[0].Id); len(v) != 2 || v[0].Data != "3" {
+		t.Fatalf("unexpected replay: %+v", v)
+	}
+	if v := hub.subscribe("news", sub, "unknown"); len(v) != 3 {
+		t.Fatalf("unexpected replay: %+v", v)
+	}
+
+	// slow subscriber is disconnected
+	hub.Broadcast(rail, "news", "msg", 5)
+	hub.Broadcast(rail, "news", "msg", 6)
+	select {
+	case <-sub.slow:
+	default:
+		t.Fatal("slow subscriber is not disconnected")
+	}
+}
+
+func TestSseHubEvictIdleChannels(t *testing.T) {
+	hub := NewSseHub(func(c *SseHubConfig) { c.IdleTimeout = 50 * time.Millisecond })
+	defer hub.Close()
+	rail := EmptyRail()
+	sub := &sseSubscriber{events: make(chan SseEvent, 10), slow: make(chan struct{})}
+	hub.subscribe("subscribed", sub, "")
+	hub.Broadcast(rail, "subscribed", "msg", 1)
+	hub.Broadcast(rail, "idle", "msg", 1)
+
+	time.Sleep(150 * time.Millisecond)
+	hub.mu.Lock()
+	_, idle := hub.channels["idle"]
+	_, subscribed := hub.channels["subscribed"]
+	hub.mu.Unlock()
+	if idle || !subscribed {
+		t.Fatalf("only idle channel should be evicted, idle: %v, subscribed: %v", idle, subscribed)
+	}
+
+	hub.unsubscribe("subscribed", sub)
+	time.Sleep(150 * time.Millisecond)
+	hub.mu.Lock()
+	n := len(hub.channels)
+	hub.mu.Unlock()
+	if n != 0 {
+		t.Fatalf("channels should be evicted after the subscriber left, %v", n)
+	}
+}
+
+func TestSseHubSubscribe(t *testing.T) {
+	prevRoutes, prevInterceptors := lazyRouteRegistars, interceptors
+	defer func() { lazyRouteRegistars, interceptors = prevRoutes, prevInterceptors }()
+	gin.SetMode(gin.TestMode)
+
+	hub := NewSseHub(func(c *SseHubConfig) { c.Heartbeat = 0 })
+	defer hub.Close()
+	decl := HttpGet("/events", RawHandler(func(inb *Inbound) {
+		if err := hub.Subscribe(inb, "news"); err != nil {
+			t.Error(err)
+		}
+	}))
+	engine := gin.New()
+	engine.Handle(decl.Method, decl.Url, decl.Handler)
+	server := httptest.NewServer(engine)
+	defer server.Close()
+
+	connect := func(lastEventId string) (*bufio.Reader, func()) {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"/events", nil)
+		if lastEventId != "" {
+			req.Header.Set(HeaderLastEventId, lastEventId)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+			t.Fatalf("unexpected content type: %v", ct)
+		}
+		return bufio.NewReader(resp.Body), func() { resp.Body.Close() }
+	}
+	readEvent := func(r *bufio.Reader) map[string]string {
+		e := map[string]string{}
+		for {
+			l, err := r.ReadString('\n')
+			if err != nil {
+				t.Fatal(err)
+			}
+			l = strings.TrimSuffix(l, "\n")
+			if l == "" {
+				return e
+			}
+			k, v, _ := strings.Cut(l, ": ")
+			e[k] = v
+		}
+	}
+	waitSubscribed := func(n int) {
+		for i := 0; i < 100; i++ {
+			hub.mu.Lock()
+			ch, ok := hub.channels["news"]
+			cnt := 0
+			if ok {
+				cnt = len(ch.subs)
+			}
+			hub.mu.Unlock()
+			if cnt == n {
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatalf("subscriber is not registered")
+	}
+
+	r, closeConn := connect("")
+	waitSubscribed(1)
+	rail := EmptyRail()
+	hub.Broadcast(rail, "news", "greeting", map[string]string{"text": "hello"})
+	e1 := readEvent(r)
+	if e1["event"] != "greeting" || e1["data"] != `{"text":"hello"}` || e1["id"] == "" {
+		t.Fatalf("unexpected event: %v", e1)
+	}
+	closeConn()
+	waitSubscribed(0)
+
+	// missed while disconnected
+	hub.Broadcast(rail, "news", "greeting", "world")
+
+	r, closeConn = connect(e1["id"])
+	defer closeConn()
+	if e2 := readEvent(r); e2["data"] != "world" {
+		t.Fatalf("unexpected event: %v", e2)
+	}
+}
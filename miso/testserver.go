@@ -0,0 +1,144 @@
+package miso
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/curtisnewbie/miso/flow"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	testServerHost = "http://miso.test"
+)
+
+// In-process test server for the declared routes.
+//
+// Requests are served by an in-memory gin engine, they go through the real middlewares, interceptors, validation and ResultBodyBuilder,
+// but no port is bound and the app is not bootstrapped.
+//
+// Use [NewTestServer] to create one.
+type TestServer struct {
+	Rail Rail
+
+	engine  *gin.Engine
+	client  *http.Client
+	headers http.Header
+}
+
+// Create TestServer with the routes declared so far, e.g., using [HttpGet], [AutoHandler] and [GroupRoute].
+//
+// Routes registered in [BeforeWebRouteRegister] callbacks (e.g., health check and pprof routes) are not included.
+// Routes for admin http server are served on the same engine.
+//
+// E.g.,
+//
+//	ts := miso.NewTestServer(t).WithUser(flow.User{UserNo: "UE1", RoleNo: "admin"})
+//	var res miso.GnResp[Order]
+//	err := ts.NewClient("/open/api/order").AddQueryParams("id", "1").Get().Json(&res)
+func NewTestServer(t *testing.T) *TestServer {
+	t.Helper()
+	rail := EmptyRail()
+	gin.SetMode(gin.TestMode)
+
+	engine, err := newServerEngine(rail)
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine.NoRoute(func(ctx *gin.Context) {
+		rail := BuildRail(ctx)
+		rail.Warnf("NoRoute for %s '%s'", ctx.Request.Method, ctx.Request.RequestURI)
+		noRouteHandler(ctx, rail)
+	})
+	for _, registerRoute := range routeRegistars {
+		registerRoute(engine)
+	}
+	for _, lrr := range lazyRouteRegistars {
+		if lrr.admin {
+			lrr.buildAdmin(engine)
+			continue
+		}
+		lrr.prepare()
+		engine.Handle(lrr.Method, lrr.Url, lrr.Handler)
+	}
+
+	return &TestServer{
+		Rail:    rail,
+		engine:  engine,
+		client:  &http.Client{Transport: testServerTransport{engine: engine}},
+		headers: http.Header{},
+	}
+}
+
+// Create a copy of TestServer that sends the header in each request.
+func (s *TestServer) WithHeader(k string, v string) *TestServer {
+	c := *s
+	c.headers = s.headers.Clone()
+	c.headers.Set(k, v)
+	return &c
+}
+
+// Create a copy of TestServer that sends requests on behalf of the user.
+//
+// The user is propagated using the same headers as the ones used between services, see [flow.XUserNo].
+func (s *TestServer) WithUser(u flow.User) *TestServer {
+	c := *s
+	c.headers = s.headers.Clone()
+	for k, v := range map[string]string{flow.XUserNo: u.UserNo, flow.XUsername: u.Username, flow.XRoleNo: u.RoleNo, flow.XRole: u.Role} {
+		if v == "" {
+			c.headers.Del(k)
+		} else {
+			c.headers.Set(k, v)
+		}
+	}
+	return &c
+}
+
+// Create a copy of TestServer that sends requests with the trace id.
+func (s *TestServer) WithTraceId(traceId string) *TestServer {
+	return s.WithHeader(flow.XTraceId, traceId)
+}
+
+// Create Client that sends requests to the TestServer, the url is the path of the route, e.g., '/open/api/order'.
+func (s *TestServer) NewClient(url string) *Client {
+	if !strings.HasPrefix(url, "/") {
+		url = "/" + url
+	}
+	c := NewClient(s.Rail, testServerHost+url).UseClient(s.client)
+	for k, v := range s.headers {
+		c.SetHeaders(k, v...)
+	}
+	return c
+}
+
+// Serve the request directly, the response is recorded.
+func (s *TestServer) ServeHTTP(r *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	for k, v := range s.headers {
+		if _, ok := r.Header[k]; !ok {
+			r.Header[k] = v
+		}
+	}
+	s.engine.ServeHTTP(w, r)
+	return w
+}
+
+// Underlying gin engine.
+func (s *TestServer) Engine() *gin.Engine {
+	return s.engine
+}
+
+// http.RoundTripper that serves requests using the gin engine in-process.
+type testServerTransport struct {
+	engine *gin.Engine
+}
+
+func (t testServerTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	w := httptest.NewRecorder()
+	t.engine.ServeHTTP(w, r)
+	resp := w.Result()
+	resp.Request = r
+	return resp, nil
+}
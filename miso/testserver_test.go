@@ -0,0 +1,70 @@
+package miso
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/curtisnewbie/miso/errs"
+	"github.com/curtisnewbie/miso/flow"
+	"github.com/gin-gonic/gin"
+)
+
+type testServerReq struct {
+	Name string `json:"name" valid:"notEmpty"`
+}
+
+type testServerRes struct {
+	Greeting string `json:"greeting"`
+	UserNo   string `json:"userNo"`
+	TraceId  string `json:"traceId"`
+	Tenant   string `json:"tenant"`
+}
+
+func TestTestServer(t *testing.T) {
+	prevRoutes, prevInterceptors := lazyRouteRegistars, interceptors
+	defer func() { lazyRouteRegistars, interceptors = prevRoutes, prevInterceptors }()
+	lazyRouteRegistars, interceptors = nil, nil
+
+	intercepted := 0
+	AddInterceptor(func(c *gin.Context, next func()) {
+		intercepted++
+		next()
+	})
+	GroupRoute("/open/api",
+		HttpPost("/greet", AutoHandler(func(inb *Inbound, req testServerReq) (testServerRes, error) {
+			rail := inb.Rail()
+			return testServerRes{
+				Greeting: "hello " + req.Name,
+				UserNo:   rail.User().UserNo,
+				TraceId:  rail.TraceId(),
+				Tenant:   inb.Header("x-tenant"),
+			}, nil
+		})),
+	)
+
+	ts := NewTestServer(t).WithUser(flow.User{UserNo: "UE1", RoleNo: "admin"}).WithTraceId("test-trace").WithHeader("x-tenant", "t1")
+	var res GnResp[testServerRes]
+	if err := ts.NewClient("/open/api/greet").PostJson(testServerReq{Name: "miso"}).Json(&res); err != nil {
+		t.Fatal(err)
+	}
+	exp := testServerRes{Greeting: "hello miso", UserNo: "UE1", TraceId: "test-trace", Tenant: "t1"}
+	if res.Data != exp {
+		t.Fatalf("unexpected response: %+v", res.Data)
+	}
+	if intercepted != 1 {
+		t.Fatalf("interceptor is not invoked")
+	}
+
+	// validation
+	err := ts.NewClient("/open/api/greet").PostJson(testServerReq{}).Json(&res)
+	if _, ok := errs.As[*MisoErr](err); !ok {
+		t.Fatalf("request should be rejected, %v", err)
+	}
+
+	// not found
+	tr := ts.NewClient("/open/api/unknown").Get()
+	if tr.StatusCode != http.StatusNotFound {
+		t.Fatalf("unexpected status: %v", tr.StatusCode)
+	}
+	tr.Close()
+}
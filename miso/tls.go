@@ -0,0 +1,290 @@
+package miso
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/curtisnewbie/miso/flow"
+)
+
+const (
+	TlsClientAuthNone             = "none"
+	TlsClientAuthRequest          = "request"
+	TlsClientAuthRequire          = "require"
+	TlsClientAuthVerifyIfGiven    = "verify-if-given"
+	TlsClientAuthRequireAndVerify = "require-and-verify"
+)
+
+func init() {
+	RegisterBootstrapCallback(ComponentBootstrap{
+		Name:      "Bootstrap HTTP Client TLS",
+		Bootstrap: clientTlsBootstrap,
+		Condition: func(rail Rail) (bool, error) { return GetPropBool(PropClientTlsEnabled), nil },
+		Order:     BootstrapOrderL1,
+	})
+}
+
+// Certificate and CA files that are reloaded when they change on disk.
+type tlsFiles struct {
+	certFile string
+	keyFile  string
+	caFile   string
+
+	// build the CA pool on top of the system cert pool, such that the public CAs are still trusted
+	systemRoots bool
+
+	mu      sync.Mutex
+	modTime time.Time
+	cert    atomic.Pointer[tls.Certificate]
+	ca      atomic.Pointer[x509.CertPool]
+}
+
+// latest modification time of the files.
+func (t *tlsFiles) lastModified() (time.Time, error) {
+	var last time.Time
+	for _, f := range []string{t.certFile, t.keyFile, t.caFile} {
+		if f == "" {
+			continue
+		}
+		fi, err := os.Stat(f)
+		if err != nil {
+			return last, err
+		}
+		if fi.ModTime().After(last) {
+			last = fi.ModTime()
+		}
+	}
+	return last, nil
+}
+
+// load the files if they are changed, previously loaded files are kept if the new ones are invalid.
+func (t *tlsFiles) reload(rail Rail) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	mt, err := t.lastModified()
+	if err != nil {
+		return err
+	}
+	if !t.modTime.IsZero() && !mt.After(t.modTime) {
+		return nil
+	}
+
+	var cert *tls.Certificate
+	if t.certFile != "" {
+		c, err := tls.LoadX509KeyPair(t.certFile, t.keyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load certificate '%v', key '%v', %w", t.certFile, t.keyFile, err)
+		}
+		cert = &c
+	}
+	var ca *x509.CertPool
+	if t.caFile != "" {
+		pem, err := os.ReadFile(t.caFile)
+		if err != nil {
+			return fmt.Errorf("failed to read CA file '%v', %w", t.caFile, err)
+		}
+		ca = x509.NewCertPool()
+		if t.systemRoots {
+			if sp, err := x509.SystemCertPool(); err == nil {
+				ca = sp
+			} else {
+				rail.Warnf("Failed to load system cert pool, only CA file '%v' is trusted, %v", t.caFile, err)
+			}
+		}
+		if !ca.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no valid certificate found in CA file '%v'", t.caFile)
+		}
+	}
+
+	if cert != nil {
+		t.cert.Store(cert)
+	}
+	if ca != nil {
+		t.ca.Store(ca)
+	}
+	if !t.modTime.IsZero() {
+		rail.Infof("Reloaded TLS certificates, cert: '%v', ca: '%v'", t.certFile, t.caFile)
+	}
+	t.modTime = mt
+	return nil
+}
+
+// reload the files periodically until app shutdown.
+func (t *tlsFiles) watch(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	AddShutdownHook(func() { close(done) })
+
+	go func() {
+		defer ticker.Stop()
+		rail := EmptyRail()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := t.reload(rail); err != nil {
+					rail.Errorf("Failed to reload TLS certificates, %v", err)
+				}
+			}
+		}
+	}()
+}
+
+func parseTlsClientAuth(v string) (tls.ClientAuthType, error) {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "", TlsClientAuthNone:
+		return tls.NoClientCert, nil
+	case TlsClientAuthRequest:
+		return tls.RequestClientCert, nil
+	case TlsClientAuthRequire:
+		return tls.RequireAnyClientCert, nil
+	case TlsClientAuthVerifyIfGiven:
+		return tls.VerifyClientCertIfGiven, nil
+	case TlsClientAuthRequireAndVerify:
+		return tls.RequireAndVerifyClientCert, nil
+	}
+	return tls.NoClientCert, fmt.Errorf("invalid client auth type: '%v'", v)
+}
+
+func parseTlsVersion(v string) (uint16, error) {
+	switch strings.TrimSpace(v) {
+	case "":
+		return 0, nil
+	case "1.0":
+		return tls.VersionTLS10, nil
+	case "1.1":
+		return tls.VersionTLS11, nil
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	}
+	return 0, fmt.Errorf("invalid TLS version: '%v'", v)
+}
+
+// Build server side *tls.Config from 'server.tls.*' props.
+//
+// Certificate and client CA files are reloaded when they change on disk.
+func newServerTlsConfig(rail Rail) (*tls.Config, error) {
+	files := &tlsFiles{
+		certFile: GetPropStr(PropServerTlsCertFile),
+		keyFile:  GetPropStr(PropServerTlsKeyFile),
+		caFile:   GetPropStr(PropServerTlsClientCaFile),
+	}
+	if files.certFile == "" || files.keyFile == "" {
+		return nil, fmt.Errorf("TLS is enabled, but '%v' or '%v' is missing", PropServerTlsCertFile, PropServerTlsKeyFile)
+	}
+	clientAuth, err := parseTlsClientAuth(GetPropStr(PropServerTlsClientAuth))
+	if err != nil {
+		return nil, err
+	}
+	if clientAuth >= tls.VerifyClientCertIfGiven && files.caFile == "" {
+		return nil, fmt.Errorf("TLS client auth '%v' requires '%v'", GetPropStr(PropServerTlsClientAuth), PropServerTlsClientCaFile)
+	}
+	minVer, err := parseTlsVersion(GetPropStr(PropServerTlsMinVersion))
+	if err != nil {
+		return nil, err
+	}
+	if err := files.reload(rail); err != nil {
+		return nil, err
+	}
+	files.watch(GetPropDuration(PropServerTlsReloadInterval))
+
+	base := &tls.Config{
+		MinVersion: minVer,
+		ClientAuth: clientAuth,
+		NextProtos: []string{"h2", "http/1.1"},
+	}
+	base.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		c := base.Clone()
+		c.GetConfigForClient = nil
+		c.Certificates = []tls.Certificate{*files.cert.Load()}
+		c.ClientCAs = files.ca.Load()
+		return c, nil
+	}
+	return base, nil
+}
+
+func clientTlsBootstrap(rail Rail) error {
+	files := &tlsFiles{
+		certFile: GetPropStr(PropClientTlsCertFile),
+		keyFile:  GetPropStr(PropClientTlsKeyFile),
+		caFile:   GetPropStr(PropClientTlsCaFile),
+
+		// the default client is also used for external services, e.g., third-party APIs
+		systemRoots: true,
+	}
+	if (files.certFile == "") != (files.keyFile == "") {
+		return fmt.Errorf("both '%v' and '%v' should be provided", PropClientTlsCertFile, PropClientTlsKeyFile)
+	}
+	if err := files.reload(rail); err != nil {
+		return err
+	}
+	files.watch(GetPropDuration(PropClientTlsReloadInterval))
+
+	tr := MisoDefaultClient.Transport.(*http.Transport)
+	if tr.TLSClientConfig == nil {
+		tr.TLSClientConfig = &tls.Config{}
+	}
+	if files.caFile != "" {
+		// CA is verified manually such that the reloaded CA is used, the system CAs are trusted as well.
+		tr.TLSClientConfig.InsecureSkipVerify = true
+		tr.TLSClientConfig.VerifyConnection = func(cs tls.ConnectionState) error {
+			return verifyServerCert(cs, files.ca.Load())
+		}
+	}
+	if files.certFile != "" {
+		tr.TLSClientConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return files.cert.Load(), nil
+		}
+	}
+	rail.Infof("HTTP Client TLS enabled, cert: '%v', ca: '%v'", files.certFile, files.caFile)
+	return nil
+}
+
+func verifyServerCert(cs tls.ConnectionState, roots *x509.CertPool) error {
+	if len(cs.PeerCertificates) < 1 {
+		return fmt.Errorf("server certificate is missing")
+	}
+	opts := x509.VerifyOptions{
+		Roots:         roots,
+		DNSName:       cs.ServerName,
+		Intermediates: x509.NewCertPool(),
+	}
+	for _, c := range cs.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(c)
+	}
+	_, err := cs.PeerCertificates[0].Verify(opts)
+	return err
+}
+
+// Build flow.PeerIdentity from the verified client certificate, returns false if client certificate is not verified.
+func tlsPeerIdentity(r *http.Request) (flow.PeerIdentity, bool) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) < 1 || len(r.TLS.VerifiedChains[0]) < 1 {
+		return flow.PeerIdentity{}, false
+	}
+	c := r.TLS.VerifiedChains[0][0]
+	p := flow.PeerIdentity{
+		CommonName:   c.Subject.CommonName,
+		Organization: c.Subject.Organization,
+		DNSNames:     c.DNSNames,
+		SerialNumber: c.SerialNumber.String(),
+		Issuer:       c.Issuer.CommonName,
+	}
+	for _, u := range c.URIs {
+		p.URIs = append(p.URIs, u.String())
+	}
+	return p, true
+}
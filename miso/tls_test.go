@@ -0,0 +1,224 @@
+package miso
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCert(t *testing.T, cn string, serial int64, parent *testCert, isCA bool) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	if isCA {
+		tmpl.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+	}
+	pc, pk := tmpl, key
+	if parent != nil {
+		pc, pk = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, pc, &key.PublicKey, pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCert{cert: c, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+func (c *testCert) write(t *testing.T, certFile string, keyFile string) {
+	kb, err := x509.MarshalECPrivateKey(c.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(certFile, c.pem, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: kb}), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestServerTlsConfig(t *testing.T) {
+	rail := EmptyRail()
+	dir := t.TempDir()
+	ca := newTestCert(t, "test-ca", 1, nil, true)
+	server := newTestCert(t, "server", 2, ca, false)
+	client := newTestCert(t, "client", 3, ca, false)
+
+	caFile := filepath.Join(dir, "ca.pem")
+	if err := os.WriteFile(caFile, ca.pem, 0600); err != nil {
+		t.Fatal(err)
+	}
+	certFile, keyFile := filepath.Join(dir, "server.pem"), filepath.Join(dir, "server.key")
+	server.write(t, certFile, keyFile)
+
+	SetProp(PropServerTlsCertFile, certFile)
+	SetProp(PropServerTlsKeyFile, keyFile)
+	SetProp(PropServerTlsClientCaFile, caFile)
+	SetProp(PropServerTlsClientAuth, TlsClientAuthRequireAndVerify)
+	SetProp(PropServerTlsReloadInterval, "50ms")
+	defer SetProp(PropServerTlsReloadInterval, "30s")
+	defer SetProp(PropServerTlsClientAuth, TlsClientAuthNone)
+
+	conf, err := newServerTlsConfig(rail)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var peer string
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if p, ok := tlsPeerIdentity(r); ok {
+			peer = p.CommonName
+		}
+	}))
+	ts.TLS = conf
+	ts.StartTLS()
+	defer ts.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	clientCert := tls.Certificate{Certificate: [][]byte{client.cert.Raw}, PrivateKey: client.key}
+	newClient := func(certs ...tls.Certificate) *http.Client {
+		return &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots, Certificates: certs}}}
+	}
+
+	// client certificate is required
+	if _, err := newClient().Get(ts.URL); err == nil {
+		t.Fatal("request without client certificate should fail")
+	}
+
+	resp, err := newClient(clientCert).Get(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if peer != "client" {
+		t.Fatalf("unexpected peer identity: '%v'", peer)
+	}
+
+	// replace the certificate on disk, it should be picked up on reload
+	renewed := newTestCert(t, "server-renewed", 4, ca, false)
+	renewed.write(t, certFile, keyFile)
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(certFile, future, future); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(300 * time.Millisecond)
+
+	resp, err = newClient(clientCert).Get(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if cn := resp.TLS.PeerCertificates[0].Subject.CommonName; cn != "server-renewed" {
+		t.Fatalf("certificate is not reloaded, got: '%v'", cn)
+	}
+}
+
+func TestTlsFilesReload(t *testing.T) {
+	rail := EmptyRail()
+	dir := t.TempDir()
+	ca := newTestCert(t, "test-ca", 1, nil, true)
+	certFile, keyFile := filepath.Join(dir, "server.pem"), filepath.Join(dir, "server.key")
+	newTestCert(t, "v1", 2, ca, false).write(t, certFile, keyFile)
+
+	files := &tlsFiles{certFile: certFile, keyFile: keyFile}
+	if err := files.reload(rail); err != nil {
+		t.Fatal(err)
+	}
+	cn := func() string {
+		c, err := x509.ParseCertificate(files.cert.Load().Certificate[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c.Subject.CommonName
+	}
+	if v := cn(); v != "v1" {
+		t.Fatalf("expected v1, got %v", v)
+	}
+
+	// invalid files are ignored, previous certificate is kept
+	if err := os.WriteFile(certFile, []byte("invalid"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(certFile, future, future)
+	if err := files.reload(rail); err == nil {
+		t.Fatal("reload should fail")
+	}
+	if v := cn(); v != "v1" {
+		t.Fatalf("expected v1, got %v", v)
+	}
+
+	newTestCert(t, "v2", 3, ca, false).write(t, certFile, keyFile)
+	future = future.Add(time.Minute)
+	os.Chtimes(certFile, future, future)
+	if err := files.reload(rail); err != nil {
+		t.Fatal(err)
+	}
+	if v := cn(); v != "v2" {
+		t.Fatalf("expected v2, got %v", v)
+	}
+}
+
+func TestClientTlsSystemRoots(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "test-ca", 1, nil, true)
+	caFile := filepath.Join(dir, "ca.pem")
+	if err := os.WriteFile(caFile, ca.pem, 0600); err != nil {
+		t.Fatal(err)
+	}
+	files := &tlsFiles{caFile: caFile, systemRoots: true}
+	if err := files.reload(EmptyRail()); err != nil {
+		t.Fatal(err)
+	}
+
+	// certificates issued by the custom CA are trusted
+	server := newTestCert(t, "server", 2, ca, false)
+	cs := tls.ConnectionState{ServerName: "localhost", PeerCertificates: []*x509.Certificate{server.cert}}
+	if err := verifyServerCert(cs, files.ca.Load()); err != nil {
+		t.Fatal(err)
+	}
+
+	// the system CAs are still trusted
+	sys, err := x509.SystemCertPool()
+	if err != nil || len(sys.Subjects()) < 1 {
+		t.Skip("system cert pool is not available")
+	}
+	if n := len(files.ca.Load().Subjects()); n != len(sys.Subjects())+1 {
+		t.Fatalf("expected %v CAs, got %v", len(sys.Subjects())+1, n)
+	}
+}
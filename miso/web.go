@@ -3,6 +3,8 @@ package miso
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -19,6 +21,7 @@ import (
 	"golang.org/x/exp/trace"
 
 	"github.com/curtisnewbie/miso/errs"
+	"github.com/curtisnewbie/miso/flow"
 	"github.com/curtisnewbie/miso/util/json"
 	"github.com/curtisnewbie/miso/util/osutil"
 	"github.com/curtisnewbie/miso/util/pair"
@@ -40,12 +43,14 @@ const (
 	ExtraJsonResponse = "miso-JsonResponse"
 	ExtraNgTable      = "miso-NgTable"
 	ExtraNoDoc        = "miso-NoDoc"
+	ExtraRateLimit    = "miso-RateLimit"
 
 	ScopePublic    = "PUBLIC"
 	ScopeProtected = "PROTECTED"
 
 	TagQueryParam  = "form"
 	TagHeaderParam = "header"
+	TagPathParam   = "path"
 )
 
 var (
@@ -79,15 +84,38 @@ var (
 	}
 
 	endpointResultHandler = func(c *gin.Context, rail Rail, payload any, err error) {
-		if err != nil {
-			dispatchJson(c, resultBodyBuilder.ErrJsonBuilder(rail, c.Request.RequestURI, err))
-			return
+		if err == nil {
+			var body any
+			if payload != nil {
+				body = resultBodyBuilder.PayloadJsonBuilder(payload)
+			} else {
+				body = resultBodyBuilder.OkJsonBuilder()
+			}
+			if err = dispatchCodec(c, http.StatusOK, body); err == nil || c.Writer.Written() {
+				if err != nil {
+					rail.Errorf("Failed to write response, %v", err)
+				}
+				return
+			}
+			// the response is not written yet, reply the encoding error instead
+		}
+
+		var body any
+		if c.GetBool(ctxKeyProblemJson) {
+			body = NewProblemDetail(rail, c.Request.RequestURI, err)
+		} else {
+			body = resultBodyBuilder.ErrJsonBuilder(rail, c.Request.RequestURI, err)
 		}
-		if payload != nil {
-			dispatchJson(c, resultBodyBuilder.PayloadJsonBuilder(payload))
+		if p, ok := body.(ProblemDetail); ok {
+			dispatchProblem(c, p)
 			return
 		}
-		dispatchJson(c, resultBodyBuilder.OkJsonBuilder())
+		if e := dispatchCodec(c, errHttpStatus(err), body); e != nil {
+			rail.Errorf("Failed to write error response, %v", e)
+			if !c.Writer.Written() {
+				c.AbortWithStatus(http.StatusInternalServerError)
+			}
+		}
 	}
 
 	// pprof / trace endpoint register disabled
@@ -113,8 +141,10 @@ type HttpRoute struct {
 	Desc        string           // description of the route (metadata).
 	Scope       string           // the documented access scope of the route, it maybe "PUBLIC" or something else (metadata).
 	Resource    string           // the documented resource that the route should be bound to (metadata).
+	RateLimit   string           // the documented rate limit of the route (metadata).
 	Headers     []ParamDoc       // the documented header parameters that will be used by the endpoint (metadata).
 	QueryParams []ParamDoc       // the documented query parameters that will used by the endpoint (metadata).
+	WebSocket   bool             // whether the endpoint upgrades the connection to websocket (metadata).
 }
 
 func init() {
@@ -128,6 +158,7 @@ func init() {
 		prepAuthInterceptors(rail)
 		prepDebugRoutes(rail)
 		prepHealthcheckRoutes()
+		prepGracefulRestart(rail)
 		// prepApiDocRoutes(rail)
 		return nil
 	})
@@ -168,11 +199,21 @@ func recordHttpServerRoute(url string, method string, extra ...pair.Pair[string,
 			r.Scope = v
 		}
 	}
+	if l, ok := extras[ExtraRateLimit]; ok && len(l) > 0 {
+		if v, ok := l[0].(string); ok {
+			r.RateLimit = v
+		}
+	}
 	if l, ok := extras[ExtraDesc]; ok && len(l) > 0 {
 		if v, ok := l[0].(string); ok {
 			r.Desc = v
 		}
 	}
+	if l, ok := extras[ExtraWebSocket]; ok && len(l) > 0 {
+		if v, ok := l[0].(bool); ok {
+			r.WebSocket = v
+		}
+	}
 	if l, ok := extras[ExtraQueryParam]; ok && len(l) > 0 {
 		for _, p := range l {
 			if v, ok := p.(ParamDoc); ok {
@@ -209,28 +250,49 @@ func prepHealthcheckRoutes() {
 
 	url := GetPropStr(PropHealthCheckUrl)
 	if !strutil.IsBlankStr(url) {
-		HttpGet(url, RawHandler(DefaultHealthCheckInbound))
+		HttpGet(url, RawHandler(DefaultHealthCheckInbound)).Admin().Public()
 	}
 }
 
 func startHttpServer(rail Rail, router http.Handler) error {
 	addr := fmt.Sprintf("%s:%s", GetPropStr(PropServerHost), GetPropStr(PropServerPort))
-	return startNetHttpServer(rail, addr, router)
+	var tlsConf *tls.Config
+	if GetPropBool(PropServerTlsEnabled) {
+		c, err := newServerTlsConfig(rail)
+		if err != nil {
+			return err
+		}
+		tlsConf = c
+	}
+	port, err := startNetHttpServer(rail, addr, router, tlsConf)
+	if err != nil {
+		return err
+	}
+	SetProp(PropServerActualPort, port)
+	return nil
 }
 
-func startNetHttpServer(rail Rail, addr string, router http.Handler) error {
+// Start http server, if tlsConf is not nil, the server serves HTTPS.
+//
+// The actual port that the server listens on is returned.
+func startNetHttpServer(rail Rail, addr string, router http.Handler, tlsConf *tls.Config) (int, error) {
 	server := &http.Server{
-		Addr:    addr,
-		Handler: router,
+		Addr:      addr,
+		Handler:   router,
+		TLSConfig: tlsConf,
 	}
 
-	ln, err := net.Listen("tcp", server.Addr)
+	ln, err := listenTcp(rail, server.Addr)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	la := ln.Addr().(*net.TCPAddr)
-	rail.Infof("Serving HTTP on %s (actual port: %d)", server.Addr, la.Port)
-	SetProp(PropServerActualPort, la.Port)
+	if tlsConf != nil {
+		ln = tls.NewListener(ln, tlsConf)
+		rail.Infof("Serving HTTPS on %s (actual port: %d)", server.Addr, la.Port)
+	} else {
+		rail.Infof("Serving HTTP on %s (actual port: %d)", server.Addr, la.Port)
+	}
 
 	go func() {
 		if err := server.Serve(ln); err != nil && err != http.ErrServerClosed {
@@ -239,11 +301,13 @@ func startNetHttpServer(rail Rail, addr string, router http.Handler) error {
 	}()
 
 	AddAsyncShutdownHook(func() { shutdownHttpServer(server) })
-	return nil
+	return la.Port, nil
 }
 
-// Register http routes on gin.Engine
-func registerServerRoutes(rail Rail, engine *gin.Engine) error {
+// Register http routes on gin.Engine.
+//
+// If adminEngine is not nil, endpoints declared with [LazyRouteDecl.Admin] are registered on adminEngine instead.
+func registerServerRoutes(rail Rail, engine *gin.Engine, adminEngine *gin.Engine) error {
 	if err := beforeRouteRegister.ForEachErr(func(t func(Rail) error) (stop bool, err error) {
 		return false, t(rail)
 	}); err != nil {
@@ -264,10 +328,22 @@ func registerServerRoutes(rail Rail, engine *gin.Engine) error {
 	routeRegistars = nil
 
 	for _, lrr := range lazyRouteRegistars {
-		lrr.build(engine)
+		if lrr.admin && adminEngine != nil {
+			lrr.buildAdmin(adminEngine)
+		} else {
+			lrr.build(engine)
+		}
 	}
 	lazyRouteRegistars = nil
 
+	logServerRoutes(rail, engine, "")
+	if adminEngine != nil {
+		logServerRoutes(rail, adminEngine, "(admin) ")
+	}
+	return nil
+}
+
+func logServerRoutes(rail Rail, engine *gin.Engine, prefix string) {
 	logRoutes := GetPropBool(PropServerLogRoutes)
 	if IsDebugLevel() || logRoutes {
 		routes := engine.Routes()
@@ -287,13 +363,12 @@ func registerServerRoutes(rail Rail, engine *gin.Engine) error {
 		})
 		for _, r := range routes {
 			if logRoutes {
-				rail.Infof("%-7s %s", r.Method, r.Path)
+				rail.Infof("%s%-7s %s", prefix, r.Method, r.Path)
 			} else {
-				rail.Debugf("%-7s %s", r.Method, r.Path)
+				rail.Debugf("%s%-7s %s", prefix, r.Method, r.Path)
 			}
 		}
 	}
-	return nil
 }
 
 func shutdownHttpServer(server *http.Server) {
@@ -331,11 +406,11 @@ func DefaultRecovery(c *gin.Context, e interface{}) {
 	}
 
 	if err, ok := e.(error); ok {
-		endpointResultHandler(c, rail, nil, err)
+		handleEndpointResult(c, rail, nil, err)
 		return
 	}
 
-	endpointResultHandler(c, rail, nil, errs.NewErrf("Unknown error, please try again later"))
+	handleEndpointResult(c, rail, nil, errs.NewErrf("Unknown error, please try again later"))
 }
 
 // Tracing Middleware
@@ -454,7 +529,7 @@ func newMappedTRouteHandler[Req any, Res any](handler MappedTRouteHandler[Req, R
 			}
 
 			if err := rfutil.WalkTagShallow(&req, wtcb...); err != nil {
-				endpointResultHandler(c, rail, nil, err)
+				handleEndpointResult(c, rail, nil, err)
 				return
 			}
 		}
@@ -467,7 +542,7 @@ func newMappedTRouteHandler[Req any, Res any](handler MappedTRouteHandler[Req, R
 		res, err := handler(inb, req)
 
 		// wrap result and error
-		endpointResultHandler(c, rail, res, err)
+		handleEndpointResult(c, rail, res, err)
 	}
 }
 
@@ -497,13 +572,21 @@ func newTRouteHandler[Res any](handler TRouteHandler[Res]) func(c *gin.Context)
 	return func(c *gin.Context) {
 		inb := newInbound(c)
 		r, e := handler(inb)
-		endpointResultHandler(c, inb.Rail(), r, e)
+		handleEndpointResult(c, inb.Rail(), r, e)
 	}
 }
 
 // Handle endpoint's result using the configured EndpointResultHandler.
 func HandleEndpointResult(inb Inbound, rail Rail, result any, err error) {
 	c := inb.Engine().(*gin.Context)
+	handleEndpointResult(c, rail, result, err)
+}
+
+func handleEndpointResult(c *gin.Context, rail Rail, result any, err error) {
+	if err != nil {
+		c.Set(ctxKeyEndpointErr, true) // e.g., response with error is not cached
+		err = requestLimitErr(c, err)
+	}
 	endpointResultHandler(c, rail, result, err)
 }
 
@@ -515,11 +598,17 @@ func mustBind(rail Rail, c *gin.Context, ptr any) {
 		} else {
 			rail.Warnf("Bind payload failed, %v", err)
 		}
+		var mbe *http.MaxBytesError
+		if errors.As(err, &mbe) {
+			panic(ErrRequestEntityTooLarge.New())
+		}
 		panic(errs.NewErrf("Illegal Arguments"))
 	}
 
-	// we now use jsoniter
-	if c.Request.Method != http.MethodGet && c.ContentType() == gin.MIMEJSON {
+	cd, hasCodec := requestCodec(c.Request)
+	switch {
+	case c.Request.Method != http.MethodGet && c.ContentType() == gin.MIMEJSON:
+		// we now use jsoniter
 		buf, readErr := io.ReadAll(c.Request.Body)
 		if readErr != nil {
 			onFailed(nil, readErr)
@@ -528,13 +617,40 @@ func mustBind(rail Rail, c *gin.Context, ptr any) {
 		if err := json.DecodeJson(bytes.NewReader(buf), ptr); err != nil {
 			onFailed(buf, err)
 		}
-		return
+
+	case c.Request.Method != http.MethodGet && c.ContentType() == gin.MIMEMultipartPOSTForm:
+		if err := bindMultipart(rail, c, ptr); err != nil {
+			if errs.IsAny(err, ErrRequestEntityTooLarge) {
+				panic(err)
+			}
+			onFailed(nil, err)
+		}
+
+	case c.Request.Method != http.MethodGet && hasCodec:
+		if err := cd.Decode(c.Request.Body, ptr); err != nil {
+			onFailed(nil, err)
+		}
+
+	default:
+		// other mime types
+		if err := c.ShouldBind(ptr); err != nil {
+			onFailed(nil, err)
+		}
 	}
 
-	// other mime types
-	if err := c.ShouldBind(ptr); err != nil {
-		onFailed(nil, err)
+	// path parameters take precedence
+	if err := bindPathParams(c, ptr); err != nil {
+		rail.Warnf("Bind path parameters failed, %v", err)
+		panic(err)
+	}
+}
+
+// Resolve http status for the error, by default it's http.StatusOK.
+func errHttpStatus(err error) int {
+	if me, ok := errs.As[*MisoErr](err); ok && me.HttpStatus() > 0 {
+		return me.HttpStatus()
 	}
+	return http.StatusOK
 }
 
 // Dispatch a json response
@@ -568,9 +684,50 @@ func webServerBootstrap(rail Rail) error {
 	}
 
 	// gin engine
+	engine, err := newServerEngine(rail)
+	if err != nil {
+		return err
+	}
+	ginPreProcessors = nil
+
+	var adminEngine *gin.Engine
+	if IsAdminServerEnabled() {
+		adminEngine = newAdminEngine(rail)
+	}
+
+	// register http routes
+	if err := registerServerRoutes(rail, engine, adminEngine); err != nil {
+		return err
+	}
+
+	// start the admin http server
+	if adminEngine != nil {
+		if err := startAdminHttpServer(rail, adminEngine); err != nil {
+			return err
+		}
+	}
+
+	// start the http server
+	return startHttpServer(rail, engine)
+}
+
+// Create gin engine with the middlewares and GinPreProcessors.
+func newServerEngine(rail Rail) (*gin.Engine, error) {
 	engine := gin.New()
 	engine.Use(TraceMiddleware())
 
+	if GetPropBool(PropServerAccessLogEnabled) {
+		engine.Use(AccessLogMiddleware())
+	}
+
+	if GetPropBool(PropServerCorsEnabled) || len(corsGroups) > 0 {
+		cors, err := CorsMiddleware()
+		if err != nil {
+			return nil, err
+		}
+		engine.Use(cors)
+	}
+
 	if !IsProdMode() && IsDebugLevel() {
 		engine.Use(gin.Logger()) // gin's default logger for debugging
 	}
@@ -579,21 +736,17 @@ func webServerBootstrap(rail Rail) error {
 		engine.Use(PerfMiddleware())
 	}
 
+	if GetPropBool(PropServerCompressionEnabled) {
+		engine.Use(CompressionMiddleware())
+	}
+
 	for _, p := range ginPreProcessors {
 		p(rail, engine)
 	}
-	ginPreProcessors = nil
 
 	// register customer recovery func
 	engine.Use(gin.RecoveryWithWriter(loggerErrOut, DefaultRecovery))
-
-	// register http routes
-	if err := registerServerRoutes(rail, engine); err != nil {
-		return err
-	}
-
-	// start the http server
-	return startHttpServer(rail, engine)
+	return engine, nil
 }
 
 type TreePath interface {
@@ -608,14 +761,57 @@ type LazyRouteDecl struct {
 
 	RegisterFunc func(extra ...pair.Pair[string, any])
 	Extras       []pair.Pair[string, any]
+
+	// route specific interceptors, invoked after the global interceptors.
+	interceptors []func(c *gin.Context, next func())
+
+	// handler without interceptors.
+	handler func(c *gin.Context)
+
+	// whether the endpoint is served on the admin http server.
+	admin bool
+
+	// whether the route specific interceptors are prepared.
+	prepared bool
+
+	// request timeout, 'server.request.timeout' is used if it's not specified.
+	timeout time.Duration
+
+	// max size of request body, 'server.request.max-body-size' is used if it's not specified.
+	maxBodySize int64
+
+	// max size of multipart request body, 'server.multipart.max-size' is used if it's not specified.
+	maxUploadSize int64
+
+	// long-lived streaming route, e.g., WebSocket or SSE, 'server.request.timeout' is not applied.
+	streaming bool
 }
 
 // Build endpoint.
 func (g *LazyRouteDecl) build(engine *gin.Engine) {
 	recordHttpServerRoute(g.Url, g.Method, g.Extras...)
+	g.prepare()
 	engine.Handle(g.Method, g.Url, g.Handler)
 }
 
+// Prepare route specific interceptors that depend on configuration, it's only done once.
+func (g *LazyRouteDecl) prepare() {
+	if g.prepared {
+		return
+	}
+	g.prepared = true
+	if GetPropBool(PropServerAuthorizationEnabled) {
+		if f, ok := g.authorizer(); ok {
+			// authorize before other route specific interceptors
+			g.interceptors = append([]func(c *gin.Context, next func()){f}, g.interceptors...)
+		}
+	}
+	if f, ok := g.requestLimiter(); ok {
+		// limits apply to all the route specific interceptors
+		g.interceptors = append([]func(c *gin.Context, next func()){f}, g.interceptors...)
+	}
+}
+
 func (g *LazyRouteDecl) Prepend(baseUrl string) {
 	g.Url = baseUrl + g.Url
 }
@@ -628,22 +824,22 @@ func (g *LazyRouteDecl) Desc(desc string) *LazyRouteDecl {
 	return g.Extra(ExtraDesc, strings.TrimSpace(regexp.MustCompile(`[\n\t ]+`).ReplaceAllString(desc, " ")))
 }
 
-// Mark endpoint publicly accessible (only serves as metadata that maybe used by some plugins).
+// Mark endpoint publicly accessible (serves as metadata that maybe used by some plugins, and by the in-process authorizer when 'server.authorization.enabled' is true).
 func (g *LazyRouteDecl) Public() *LazyRouteDecl {
 	return g.Extra(ExtraScope, ScopePublic)
 }
 
-// Document the access scope of the endpoint (only serves as metadata that maybe used by some plugins).
+// Document the access scope of the endpoint (serves as metadata that maybe used by some plugins, and by the in-process authorizer when 'server.authorization.enabled' is true).
 func (g *LazyRouteDecl) Scope(scope string) *LazyRouteDecl {
 	return g.Extra(ExtraScope, scope)
 }
 
-// Documents that the endpoint requires protection (only serves as metadata that maybe used by some plugins).
+// Documents that the endpoint requires protection (serves as metadata that maybe used by some plugins, and by the in-process authorizer when 'server.authorization.enabled' is true).
 func (g *LazyRouteDecl) Protected() *LazyRouteDecl {
 	return g.Extra(ExtraScope, ScopeProtected)
 }
 
-// Record the resource that the endppoint should be bound to (only serves as metadata that maybe used by some plugins).
+// Record the resource that the endppoint should be bound to (serves as metadata that maybe used by some plugins, and by the in-process authorizer when 'server.authorization.enabled' is true, see [SetPermissionChecker]).
 func (g *LazyRouteDecl) Resource(resource string) *LazyRouteDecl {
 	return g.Extra(ExtraResource, strings.TrimSpace(resource))
 }
@@ -653,6 +849,12 @@ func (g *LazyRouteDecl) NoDoc() *LazyRouteDecl {
 	return g.Extra(ExtraNoDoc, true)
 }
 
+// Add route specific interceptor, it's invoked after the global interceptors registered using [AddInterceptor].
+func (g *LazyRouteDecl) intercept(f func(c *gin.Context, next func())) *LazyRouteDecl {
+	g.interceptors = append(g.interceptors, f)
+	return g
+}
+
 // Add extra info to endpoint's metadata.
 func (g *LazyRouteDecl) Extra(key string, value any) *LazyRouteDecl {
 	return g.extra(key, value, nil)
@@ -745,8 +947,13 @@ func newLazyRouteDecl(url string, method string, handler func(c *gin.Context)) *
 	dec := &LazyRouteDecl{
 		Url:     url,
 		Method:  method,
-		Handler: interceptedHandler(handler),
 		Extras:  []pair.Pair[string, any]{},
+		handler: handler,
+	}
+	dec.Handler = func(c *gin.Context) {
+		defer removeUploadTmpFiles(c)
+		interceptors := newInterceptor(c, handler, dec.interceptors...)
+		interceptors.next()
 	}
 	lazyRouteRegistars = append(lazyRouteRegistars, dec)
 	return dec
@@ -755,6 +962,9 @@ func newLazyRouteDecl(url string, method string, handler func(c *gin.Context)) *
 type RoutingGroup struct {
 	Base  string
 	Paths []TreePath
+
+	// base urls prepended by the parent groups.
+	prepended string
 }
 
 // Group routes, routes are immediately registered
@@ -770,11 +980,17 @@ func (rg *RoutingGroup) Group(grouped ...TreePath) *RoutingGroup {
 }
 
 func (rg *RoutingGroup) Prepend(baseUrl string) {
+	rg.prepended = baseUrl + rg.prepended
 	for _, r := range rg.Paths {
 		r.Prepend(baseUrl)
 	}
 }
 
+// full base url of the group, including the base urls of the parent groups.
+func (rg *RoutingGroup) fullBase() string {
+	return rg.prepended + rg.Base
+}
+
 // Group routes together to share the same base url.
 func GroupRoute(baseUrl string, grouped ...TreePath) *RoutingGroup {
 	return BaseRoute(baseUrl).Group(grouped...)
@@ -827,6 +1043,52 @@ func reflectSetHeaderValue(fieldVal reflect.Value, fieldType reflect.Type, heade
 	}
 }
 
+// Set string value to the field, returns error if the value can't be converted to the field's type.
+func reflectSetStrValue(fieldVal reflect.Value, fieldType reflect.Type, v string) error {
+	switch fieldType.Kind() {
+	case reflect.String:
+		fieldVal.SetString(v)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		vv, err := cast.ToInt64E(v)
+		if err != nil {
+			return err
+		}
+		if fieldVal.OverflowInt(vv) {
+			return fmt.Errorf("value %v overflows %v", v, fieldType)
+		}
+		fieldVal.SetInt(vv)
+	case reflect.Float32, reflect.Float64:
+		vv, err := cast.ToFloat64E(v)
+		if err != nil {
+			return err
+		}
+		fieldVal.SetFloat(vv)
+	case reflect.Bool:
+		vv, err := cast.ToBoolE(v)
+		if err != nil {
+			return err
+		}
+		fieldVal.SetBool(vv)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		vv, err := cast.ToUint64E(v)
+		if err != nil {
+			return err
+		}
+		if fieldVal.OverflowUint(vv) {
+			return fmt.Errorf("value %v overflows %v", v, fieldType)
+		}
+		fieldVal.SetUint(vv)
+	case reflect.Pointer:
+		ptrType := fieldType.Elem()
+		pv := reflect.New(ptrType)
+		if err := reflectSetStrValue(pv.Elem(), ptrType, v); err != nil {
+			return err
+		}
+		fieldVal.Set(pv)
+	}
+	return nil
+}
+
 func walkHeaderTagCallback(getHeader func(k string) string) rfutil.WalkTagCallback {
 	return rfutil.WalkTagCallback{
 		Tag: TagHeaderParam,
@@ -865,9 +1127,12 @@ type Inbound struct {
 
 func newInbound(c *gin.Context) *Inbound {
 	rail := BuildRail(c)
-	if GetPropBool(PropServerHandlerWithNewContext) {
+	if GetPropBool(PropServerHandlerWithNewContext) && !c.GetBool(ctxKeyRequestTimeout) {
 		rail = rail.NewCtx()
 	}
+	if p, ok := tlsPeerIdentity(c.Request); ok {
+		rail = flow.StorePeerIdentity(rail, p)
+	}
 	return &Inbound{
 		erail:  rail,
 		engine: c,
@@ -906,6 +1171,16 @@ func (i *Inbound) Rail() Rail {
 	return i.erail
 }
 
+// Resolve client ip, X-Forwarded-For and X-Real-Ip headers are also checked.
+func (i *Inbound) ClientIP() string {
+	return i.engine.ClientIP()
+}
+
+// Resolve ip of the remote peer, i.e., the ip of the direct connection, headers like X-Forwarded-For are not checked.
+func (i *Inbound) RemoteIP() string {
+	return i.engine.RemoteIP()
+}
+
 /*
 Handle the result using universally configured endpoint result handler.
 
@@ -997,6 +1272,9 @@ func (i *Inbound) WriteJsonStatus(v any, httpStatus int) {
 	i.WriteJson(v)
 }
 
+// Log request, including headers and body.
+//
+// Deprecated: use structured access log instead, see 'server.access-log.*' props.
 func (i *Inbound) LogRequest() {
 	rail := i.Rail()
 	_, r := i.Unwrap()
@@ -1096,8 +1374,19 @@ func (it *interceptor) next() {
 	}
 }
 
-func newInterceptor(c *gin.Context, handler func(c *gin.Context)) *interceptor {
-	copy := slutil.Copy(interceptors)
+func newInterceptor(c *gin.Context, handler func(c *gin.Context), routeInterceptors ...func(c *gin.Context, next func())) *interceptor {
+	return newInterceptorChain(c, handler, interceptors, routeInterceptors)
+}
+
+func newInterceptorChain(c *gin.Context, handler func(c *gin.Context), chains ...[]func(c *gin.Context, next func())) *interceptor {
+	n := 1
+	for _, ch := range chains {
+		n += len(ch)
+	}
+	copy := make([]func(c *gin.Context, next func()), 0, n)
+	for _, ch := range chains {
+		copy = append(copy, ch...)
+	}
 	return &interceptor{
 		idx:          -1,
 		c:            c,
@@ -1105,13 +1394,6 @@ func newInterceptor(c *gin.Context, handler func(c *gin.Context)) *interceptor {
 	}
 }
 
-func interceptedHandler(f func(c *gin.Context)) func(c *gin.Context) {
-	return func(c *gin.Context) {
-		interceptors := newInterceptor(c, f)
-		interceptors.next()
-	}
-}
-
 func MatchPathPatternFunc(patterns ...string) func(method string, url string) bool {
 	return func(method string, url string) bool {
 		return strutil.MatchPathAny(patterns, url)
@@ -1158,6 +1440,9 @@ func AddBearerAuthInterceptor(doIntercept func(method string, url string) bool,
 	})
 }
 
+// Allow CORS requests from any origin without credentials.
+//
+// Deprecated: use [CorsPolicy] configured using 'server.cors.*' props instead.
 func AddCorsAny() {
 	PreProcessGin(func(rail Rail, engine *gin.Engine) {
 		engine.Use(func(c *gin.Context) {
@@ -1440,28 +1725,33 @@ func newFlightRecorder(out string) *flightRecorder {
 func prepDebugRoutes(rail Rail) {
 	if !pprofRegisterDisabled && (!IsProdMode() || GetPropBool(PropServerPprofEnabled)) {
 		GroupRoute("/debug/pprof",
-			HttpGet("", RawHandler(func(inb *Inbound) { pprof.Index(inb.Unwrap()) })),
-			HttpGet("/:name", RawHandler(func(inb *Inbound) { pprof.Index(inb.Unwrap()) })),
-			HttpGet("/cmdline", RawHandler(func(inb *Inbound) { pprof.Cmdline(inb.Unwrap()) })),
-			HttpGet("/profile", RawHandler(func(inb *Inbound) { pprof.Profile(inb.Unwrap()) })),
-			HttpGet("/symbol", RawHandler(func(inb *Inbound) { pprof.Symbol(inb.Unwrap()) })),
-			HttpGet("/trace", RawHandler(func(inb *Inbound) { pprof.Trace(inb.Unwrap()) })),
+			HttpGet("", RawHandler(func(inb *Inbound) { pprof.Index(inb.Unwrap()) })).Admin(),
+			HttpGet("/:name", RawHandler(func(inb *Inbound) { pprof.Index(inb.Unwrap()) })).Admin(),
+			HttpGet("/cmdline", RawHandler(func(inb *Inbound) { pprof.Cmdline(inb.Unwrap()) })).Admin(),
+			HttpGet("/profile", RawHandler(func(inb *Inbound) { pprof.Profile(inb.Unwrap()) })).Admin(),
+			HttpGet("/symbol", RawHandler(func(inb *Inbound) { pprof.Symbol(inb.Unwrap()) })).Admin(),
+			HttpGet("/trace", RawHandler(func(inb *Inbound) { pprof.Trace(inb.Unwrap()) })).Admin(),
 		)
 		rail.Infof("Registered /debug/pprof APIs for debugging")
 
 		HttpGet("/debug/trace/recorder/run", RawHandler(HandleFlightRecorderRun)).
 			DocQueryParam("duration", "Duration of the flight recording. Required. Duration cannot exceed 30 min.").
-			Desc("Start FlightRecorder. Recorded result is written to trace.out when it's finished or stopped.")
+			Desc("Start FlightRecorder. Recorded result is written to trace.out when it's finished or stopped.").
+			Admin()
 
 		HttpGet("/debug/trace/recorder/snapshot", RawHandler(HandleFlightRecorderSnapshot)).
-			Desc("FlightRecorder take snapshot. Recorded result is written to trace.out.")
+			Desc("FlightRecorder take snapshot. Recorded result is written to trace.out.").
+			Admin()
 
 		HttpGet("/debug/trace/recorder/stop", RawHandler(HandleFlightRecorderStop)).
-			Desc("Stop existing FlightRecorder session.")
+			Desc("Stop existing FlightRecorder session.").
+			Admin()
 
 		rail.Infof("Registered /debug/trace APIs for debugging")
 
-		if GetPropStrTrimmed(PropServerAuthBearer) != "" { // server.auth.bearer is already set for all apis
+		if IsAdminServerEnabled() { // served on admin http server, protected by server.admin.auth.bearer
+			rail.Infof("pprof & trace APIs are served on admin http server")
+		} else if GetPropStrTrimmed(PropServerAuthBearer) != "" { // server.auth.bearer is already set for all apis
 			rail.Infof("Using configuration '%v' in authentication interceptor for pprof & trace APIs", PropServerAuthBearer)
 		} else {
 			// we have set auth bearer for pprof apis specifically
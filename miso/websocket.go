@@ -0,0 +1,212 @@
+package miso
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/curtisnewbie/miso/errs"
+	"github.com/curtisnewbie/miso/util/json"
+	"github.com/curtisnewbie/miso/util/rfutil"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	ExtraWebSocket = "miso-WebSocket"
+)
+
+var (
+	wsConns         = map[*websocket.Conn]struct{}{}
+	wsConnsMu       sync.Mutex
+	wsShutdownHooks sync.Once
+)
+
+// WebSocket connection, messages are encoded as JSON.
+//
+// Req is the type of messages received from the client, Res is the type of messages sent to the client.
+//
+// It's safe to call [WsConn.Send] concurrently, but [WsConn.Receive] should only be called by one goroutine.
+type WsConn[Req any, Res any] struct {
+	Rail Rail // Rail with trace and user propagated from the upgrade request.
+
+	conn         *websocket.Conn
+	writeMu      sync.Mutex
+	writeTimeout time.Duration
+}
+
+// Receive next message from the client.
+//
+// If the connection is closed by the client normally, [websocket.CloseError] is returned, which can be checked using [IsWsClosed].
+func (w *WsConn[Req, Res]) Receive() (Req, error) {
+	var req Req
+	_, b, err := w.conn.ReadMessage()
+	if err != nil {
+		return req, err
+	}
+	if err := json.ParseJson(b, &req); err != nil {
+		return req, errs.Wrapf(err, "failed to unmarshal websocket message")
+	}
+	return req, nil
+}
+
+// Send message to the client.
+func (w *WsConn[Req, Res]) Send(v Res) error {
+	b, err := json.WriteJson(v)
+	if err != nil {
+		return errs.Wrapf(err, "failed to marshal websocket message")
+	}
+	return w.write(websocket.TextMessage, b)
+}
+
+// Close the connection gracefully with the close code and reason, e.g., [websocket.CloseNormalClosure].
+func (w *WsConn[Req, Res]) Close(code int, reason string) error {
+	err := w.write(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason))
+	return errors.Join(err, w.conn.Close())
+}
+
+// Underlying gorilla websocket connection.
+func (w *WsConn[Req, Res]) Unwrap() *websocket.Conn {
+	return w.conn
+}
+
+func (w *WsConn[Req, Res]) write(messageType int, data []byte) error {
+	w.writeMu.Lock()
+	defer w.writeMu.Unlock()
+	if err := w.conn.SetWriteDeadline(time.Now().Add(w.writeTimeout)); err != nil {
+		return err
+	}
+	return w.conn.WriteMessage(messageType, data)
+}
+
+// Check whether the error is caused by the websocket connection being closed.
+func IsWsClosed(err error) bool {
+	var ce *websocket.CloseError
+	return errors.As(err, &ce) || errors.Is(err, websocket.ErrCloseSent)
+}
+
+// Handler of WebSocket connection.
+//
+// When the handler returns, the connection is closed.
+type WsHandler[Req any, Res any] func(inb *Inbound, conn *WsConn[Req, Res]) error
+
+// Register GET route that upgrades the connection to WebSocket.
+//
+// The upgrade request goes through interceptors as usual, the connection is then kept alive using ping/pong messages,
+// see 'server.websocket.*' props. Connections are closed with 1001 (Going Away) when the app shuts down.
+func HttpWs[Req any, Res any](url string, handler WsHandler[Req, Res]) *LazyRouteDecl {
+	wsShutdownHooks.Do(func() { AddShutdownHook(closeWsConns) })
+	upgrader := &websocket.Upgrader{CheckOrigin: checkWsOrigin}
+	decl := newLazyRouteDecl(url, http.MethodGet, func(c *gin.Context) {
+		serveWs(c, upgrader, handler)
+	})
+	decl.streaming = true
+	return decl.Extra(ExtraWebSocket, true).
+		DocJsonReq(rfutil.NewVar[Req]()).
+		DocJsonResp(rfutil.NewVar[Res]())
+}
+
+func serveWs[Req any, Res any](c *gin.Context, upgrader *websocket.Upgrader, handler WsHandler[Req, Res]) {
+	inb := newInbound(c)
+	rail := inb.Rail()
+
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// response is already written by the upgrader
+		rail.Warnf("Failed to upgrade websocket connection, %v", err)
+		return
+	}
+	addWsConn(conn)
+	defer removeWsConn(conn)
+
+	ws := &WsConn[Req, Res]{
+		Rail:         rail,
+		conn:         conn,
+		writeTimeout: GetPropDuration(PropServerWebSocketWriteTimeout),
+	}
+	readTimeout := GetPropDuration(PropServerWebSocketReadTimeout)
+	conn.SetReadLimit(int64(GetPropInt(PropServerWebSocketMaxMessageSize)))
+	if readTimeout > 0 {
+		conn.SetReadDeadline(time.Now().Add(readTimeout))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(readTimeout))
+		})
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	if pi := GetPropDuration(PropServerWebSocketPingInterval); pi > 0 {
+		go pingWs(ws, pi, done)
+	}
+
+	rail.Debugf("WebSocket connection established, remote: %v", conn.RemoteAddr())
+	if err := handler(inb, ws); err != nil && !IsWsClosed(err) {
+		rail.Errorf("WebSocket handler failed, %v", err)
+		ws.Close(websocket.CloseInternalServerErr, "internal server error")
+		return
+	}
+	ws.Close(websocket.CloseNormalClosure, "")
+	rail.Debugf("WebSocket connection closed, remote: %v", conn.RemoteAddr())
+}
+
+func pingWs[Req any, Res any](ws *WsConn[Req, Res], interval time.Duration, done chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if err := ws.write(websocket.PingMessage, nil); err != nil {
+				ws.Rail.Debugf("Failed to ping websocket connection, %v", err)
+				return
+			}
+		}
+	}
+}
+
+// check origin of the upgrade request based on 'server.websocket.allowed-origins'.
+func checkWsOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	allowed := GetPropStrSlice(PropServerWebSocketAllowedOrigins)
+	for _, a := range allowed {
+		if a == "*" || strings.EqualFold(a, origin) {
+			return true
+		}
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+func addWsConn(conn *websocket.Conn) {
+	wsConnsMu.Lock()
+	defer wsConnsMu.Unlock()
+	wsConns[conn] = struct{}{}
+}
+
+func removeWsConn(conn *websocket.Conn) {
+	wsConnsMu.Lock()
+	defer wsConnsMu.Unlock()
+	delete(wsConns, conn)
+}
+
+// close all websocket connections with 1001 (Going Away).
+func closeWsConns() {
+	wsConnsMu.Lock()
+	defer wsConnsMu.Unlock()
+	if len(wsConns) > 0 {
+		Infof("Closing %d websocket connections", len(wsConns))
+	}
+	msg := websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down")
+	deadline := time.Now().Add(time.Second)
+	for conn := range wsConns {
+		conn.WriteControl(websocket.CloseMessage, msg, deadline)
+		conn.Close()
+	}
+}
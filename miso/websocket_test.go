@@ -0,0 +1,104 @@
+package miso
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+type wsTestReq struct {
+	Text string `json:"text"`
+}
+
+type wsTestRes struct {
+	Echo    string `json:"echo"`
+	TraceId string `json:"traceId"`
+}
+
+func TestHttpWs(t *testing.T) {
+	prevRoutes, prevInterceptors := lazyRouteRegistars, interceptors
+	defer func() { lazyRouteRegistars, interceptors = prevRoutes, prevInterceptors }()
+	gin.SetMode(gin.TestMode)
+	SetProp(PropServerWebSocketPingInterval, "50ms")
+	defer SetProp(PropServerWebSocketPingInterval, "30s")
+
+	decl := HttpWs("/ws", func(inb *Inbound, conn *WsConn[wsTestReq, wsTestRes]) error {
+		for {
+			req, err := conn.Receive()
+			if err != nil {
+				return err
+			}
+			if req.Text == "bye" {
+				return nil
+			}
+			if err := conn.Send(wsTestRes{Echo: req.Text, TraceId: conn.Rail.TraceId()}); err != nil {
+				return err
+			}
+		}
+	})
+	if !decl.Extras[0].Right.(bool) {
+		t.Fatal("websocket route is not marked")
+	}
+
+	engine := gin.New()
+	engine.Use(TraceMiddleware())
+	engine.Handle(decl.Method, decl.Url, decl.Handler)
+	server := httptest.NewServer(engine)
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+	header := http.Header{}
+	header.Set(XTraceId, "test-trace")
+	conn, _, err := websocket.DefaultDialer.Dial(url, header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	pinged := make(chan struct{}, 1)
+	conn.SetPingHandler(func(string) error {
+		select {
+		case pinged <- struct{}{}:
+		default:
+		}
+		return nil
+	})
+
+	if err := conn.WriteJSON(wsTestReq{Text: "hello"}); err != nil {
+		t.Fatal(err)
+	}
+	var res wsTestRes
+	if err := conn.ReadJSON(&res); err != nil {
+		t.Fatal(err)
+	}
+	if res.Echo != "hello" || res.TraceId != "test-trace" {
+		t.Fatalf("unexpected response: %+v", res)
+	}
+
+	// pings are handled while reading the close message
+	time.Sleep(150 * time.Millisecond)
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	if err := conn.WriteJSON(wsTestReq{Text: "bye"}); err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = conn.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+		t.Fatalf("expected normal closure, got %v", err)
+	}
+	select {
+	case <-pinged:
+	default:
+		t.Fatal("no ping received")
+	}
+
+	// cross origin upgrade is rejected by default
+	header.Set("Origin", "http://evil.example.com")
+	if _, resp, err := websocket.DefaultDialer.Dial(url, header); err == nil || resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("cross origin upgrade should be rejected, %v", err)
+	}
+}